@@ -22,4 +22,26 @@ type Alumni struct {
 type JumlahAngkatan struct {
 	Angkatan int `bson:"_id" json:"angkatan"`
 	Jumlah   int `bson:"jumlah" json:"jumlah"`
-}
\ No newline at end of file
+}
+
+// AlumniJumlahPekerjaan adalah baris laporan jumlah pekerjaan per alumni
+type AlumniJumlahPekerjaan struct {
+	ID              primitive.ObjectID `bson:"_id" json:"id"`
+	NIM             string             `bson:"nim" json:"nim"`
+	Nama            string             `bson:"nama" json:"nama"`
+	JumlahPekerjaan int                `bson:"jumlah_pekerjaan" json:"jumlah_pekerjaan"`
+}
+
+// AlumniDetail adalah alumni beserta data relasi hasil ?expand=
+type AlumniDetail struct {
+	Alumni    `bson:",inline"`
+	Pekerjaan []PekerjaanAlumni `bson:"pekerjaan,omitempty" json:"pekerjaan,omitempty"`
+}
+
+// AlumniFilter adalah filter listing alumni, dipakai juga oleh endpoint statistik
+type AlumniFilter struct {
+	Search     string // regex case-insensitive pada nama dan jurusan
+	Jurusan    string
+	Angkatan   int
+	TahunLulus int
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Blob adalah isi file yang disimpan sekali per SHA-256. Beberapa metadata
+// File boleh menunjuk blob yang sama; blob dihapus saat RefCount habis.
+type Blob struct {
+	ID         string                 `json:"sha256" bson:"_id"`
+	StorageKey string                 `json:"storage_key" bson:"storage_key"`
+	Size       int64                  `json:"size" bson:"size"`
+	FileType   string                 `json:"file_type" bson:"file_type"`
+	Variants   map[string]FileVariant `json:"variants,omitempty" bson:"variants,omitempty"`
+	RefCount   int                    `json:"ref_count" bson:"ref_count"`
+	CreatedAt  time.Time              `json:"created_at" bson:"created_at"`
+}
+
+// DedupStats adalah ringkasan penghematan ruang dari deduplikasi
+type DedupStats struct {
+	Blobs         int64 `json:"blobs" bson:"blobs"`
+	References    int64 `json:"references" bson:"references"`
+	StoredBytes   int64 `json:"stored_bytes" bson:"stored_bytes"`
+	LogicalBytes  int64 `json:"logical_bytes" bson:"logical_bytes"`
+	SavedBytes    int64 `json:"saved_bytes" bson:"saved_bytes"`
+	DuplicateRefs int64 `json:"duplicate_refs" bson:"duplicate_refs"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis masalah yang ditemukan pemeriksa konsistensi file
+const (
+	IssueMissingBlob  = "missing_blob"       // metadata ada, isi di storage tidak ada
+	IssueOrphanBlob   = "orphan_blob"        // isi di storage tanpa metadata
+	IssueSizeMismatch = "size_mismatch"      // ukuran di metadata berbeda dengan storage
+	IssueRefCount     = "ref_count_mismatch" // ref_count blob berbeda dengan jumlah file
+)
+
+// Tindakan pemeriksa konsistensi
+const (
+	ConsistencyActionReport     = "report"
+	ConsistencyActionQuarantine = "quarantine"
+	ConsistencyActionClean      = "clean"
+)
+
+// ConsistencyIssue adalah satu ketidaksesuaian antara koleksi files / blobs dan storage
+type ConsistencyIssue struct {
+	Type     string              `json:"type"`
+	Key      string              `json:"key,omitempty"`
+	FileID   *primitive.ObjectID `json:"file_id,omitempty"`
+	SHA256   string              `json:"sha256,omitempty"`
+	Expected int64               `json:"expected,omitempty"`
+	Actual   int64               `json:"actual,omitempty"`
+	Action   string              `json:"action,omitempty"` // quarantined, deleted, fixed
+	Error    string              `json:"error,omitempty"`
+}
+
+// ConsistencyReport adalah hasil satu kali pemeriksaan
+type ConsistencyReport struct {
+	Action     string             `json:"action"`
+	DryRun     bool               `json:"dry_run"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	Files      int                `json:"files"`
+	Blobs      int                `json:"blobs"`
+	Objects    int                `json:"objects"`
+	Summary    map[string]int     `json:"summary"`
+	Issues     []ConsistencyIssue `json:"issues"`
+}
@@ -6,24 +6,124 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Entitas yang bisa ditautkan dengan file
+const (
+	EntityAlumni    = "alumni"
+	EntityPekerjaan = "pekerjaan"
+)
+
+// Kategori dokumen yang diizinkan per entitas ("" = file lepas tanpa entitas)
+var KategoriFile = map[string][]string{
+	"":              {"laporan", "lainnya"},
+	EntityAlumni:    {"cv", "ijazah", "transkrip", "foto", "laporan", "lainnya"},
+	EntityPekerjaan: {"surat_keterangan_kerja", "kontrak", "lainnya"},
+}
+
+// Batas ukuran default per kategori, "*" untuk kategori yang tidak disebut.
+// Bisa ditimpa lewat FILE_SIZE_LIMITS, misalnya "ijazah=50MB,*=10MB".
+var BatasUkuranFile = map[string]int64{
+	"*":                      10 << 20,
+	"ijazah":                 50 << 20,
+	"transkrip":              50 << 20,
+	"laporan":                50 << 20,
+	"surat_keterangan_kerja": 25 << 20,
+	"kontrak":                25 << 20,
+}
+
+// Status pemindaian malware. File lama tanpa scan_status ditandai pending_scan
+// saat aplikasi start (atau lewat /admin/migrations/file-scan-status).
+const (
+	ScanPending  = "pending_scan"
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+)
+
 // File adalah model untuk data di MongoDB
 type File struct {
-	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	FileName     string             `json:"file_name" bson:"file_name"`
-	OriginalName string             `json:"original_name" bson:"original_name"`
-	FilePath     string             `json:"file_path" bson:"file_path"`
-	FileSize     int64              `json:"file_size" bson:"file_size"`
-	FileType     string             `json:"file_type" bson:"file_type"`
-	UploadedAt   time.Time          `json:"uploaded_at" bson:"uploaded_at"`
+	ID           primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	FileName     string                 `json:"file_name" bson:"file_name"`
+	OriginalName string                 `json:"original_name" bson:"original_name"`
+	StorageKey   string                 `json:"storage_key" bson:"storage_key"`
+	LegacyPath   string                 `json:"-" bson:"file_path,omitempty"` // path OS pada data lama sebelum storage_key
+	FileSize     int64                  `json:"file_size" bson:"file_size"`
+	FileType     string                 `json:"file_type" bson:"file_type"`
+	SHA256       string                 `json:"sha256,omitempty" bson:"sha256,omitempty"` // kosong untuk file lama sebelum deduplikasi
+	Category     string                 `json:"category" bson:"category"`
+	EntityType   string                 `json:"entity_type,omitempty" bson:"entity_type,omitempty"`
+	EntityID     *primitive.ObjectID    `json:"entity_id,omitempty" bson:"entity_id,omitempty"`
+	UploadedBy   *primitive.ObjectID    `json:"uploaded_by,omitempty" bson:"uploaded_by,omitempty"`
+	Variants     map[string]FileVariant `json:"variants,omitempty" bson:"variants,omitempty"`
+	ScanStatus   string                 `json:"scan_status,omitempty" bson:"scan_status,omitempty"`
+	ScanResult   string                 `json:"scan_result,omitempty" bson:"scan_result,omitempty"` // nama malware bila infected
+	ScannedAt    *time.Time             `json:"scanned_at,omitempty" bson:"scanned_at,omitempty"`
+	UploadedAt   time.Time              `json:"uploaded_at" bson:"uploaded_at"`
+}
+
+// Downloadable: file baru boleh diunduh setelah dipindai bersih
+func (f *File) Downloadable() bool {
+	return f.ScanStatus == "" || f.ScanStatus == ScanClean
+}
+
+// Ukuran variant gambar yang dibuat saat upload (lebar x tinggi maksimum)
+var UkuranVariant = map[string][2]int{
+	"thumb": {200, 200},
+	"web":   {1280, 1280},
+}
+
+// FileVariant adalah versi kecil gambar (thumbnail, ukuran web) tanpa metadata EXIF
+type FileVariant struct {
+	StorageKey string `json:"storage_key" bson:"storage_key"`
+	FileSize   int64  `json:"file_size" bson:"file_size"`
+	FileType   string `json:"file_type" bson:"file_type"`
+	Width      int    `json:"width" bson:"width"`
+	Height     int    `json:"height" bson:"height"`
+}
+
+// Key mengembalikan key storage; data lama tanpa storage_key memakai nama file
+// di folder upload lokal
+func (f *File) Key() string {
+	if f.StorageKey != "" {
+		return f.StorageKey
+	}
+	return f.FileName
 }
 
 // FileResponse adalah model untuk response JSON
 type FileResponse struct {
-	ID           string    `json:"id"`
-	FileName     string    `json:"file_name"`
-	OriginalName string    `json:"original_name"`
-	FilePath     string    `json:"file_path"`
-	FileSize     int64     `json:"file_size"`
-	FileType     string    `json:"file_type"`
-	UploadedAt   time.Time `json:"uploaded_at"`
-}
\ No newline at end of file
+	ID           string            `json:"id"`
+	FileName     string            `json:"file_name"`
+	OriginalName string            `json:"original_name"`
+	FileSize     int64             `json:"file_size"`
+	FileType     string            `json:"file_type"`
+	Category     string            `json:"category"`
+	EntityType   string            `json:"entity_type,omitempty"`
+	EntityID     string            `json:"entity_id,omitempty"`
+	UploadedBy   string            `json:"uploaded_by,omitempty"`
+	DownloadURL  string            `json:"download_url"`
+	Variants     map[string]string `json:"variants,omitempty"` // nama variant -> URL download
+	ScanStatus   string            `json:"scan_status,omitempty"`
+	UploadedAt   time.Time         `json:"uploaded_at"`
+}
+
+// FileFilter adalah parameter listing file (pagination dan filter)
+type FileFilter struct {
+	Page           int
+	Limit          int
+	UploadedBy     *primitive.ObjectID
+	FileType       string // MIME lengkap ("application/pdf") atau jenisnya saja ("image")
+	Category       string
+	EntityType     string
+	EntityID       *primitive.ObjectID
+	UploadedAfter  *time.Time
+	UploadedBefore *time.Time
+	ScanStatus     string
+}
+
+// StorageUsage adalah total file yang diupload satu user. Ukuran dihitung per
+// metadata, jadi file duplikat tetap dihitung walau blob-nya disimpan sekali.
+type StorageUsage struct {
+	UploadedBy *primitive.ObjectID `json:"uploaded_by,omitempty" bson:"_id"`
+	Username   string              `json:"username,omitempty" bson:"username,omitempty"`
+	Files      int64               `json:"files" bson:"files"`
+	Bytes      int64               `json:"bytes" bson:"bytes"`
+}
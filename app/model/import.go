@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status baris hasil import
+const (
+	ImportInserted = "inserted"
+	ImportUpdated  = "updated"
+	ImportRejected = "rejected"
+)
+
+// Status job import
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// ImportRow adalah hasil import satu baris file
+type ImportRow struct {
+	Baris  int    `bson:"baris" json:"baris"`
+	NIM    string `bson:"nim" json:"nim"`
+	Status string `bson:"status" json:"status"`
+	Alasan string `bson:"alasan,omitempty" json:"alasan,omitempty"`
+}
+
+// ImportJob adalah laporan import alumni. File besar diproses di background
+// dan progresnya disimpan di koleksi import_jobs.
+type ImportJob struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Status        string             `bson:"status" json:"status"`
+	DryRun        bool               `bson:"dry_run" json:"dry_run"`
+	FileName      string             `bson:"file_name" json:"file_name"`
+	CreatedBy     string             `bson:"created_by" json:"created_by"`
+	Total         int                `bson:"total" json:"total"`
+	Inserted      int                `bson:"inserted" json:"inserted"`
+	Updated       int                `bson:"updated" json:"updated"`
+	Rejected      int                `bson:"rejected" json:"rejected"`
+	Rows          []ImportRow        `bson:"rows" json:"rows"`
+	RowsTruncated bool               `bson:"rows_truncated,omitempty" json:"rows_truncated,omitempty"`
+	Error         string             `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	FinishedAt    *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// MigrationResult adalah laporan migrasi data satu kali
+type MigrationResult struct {
+	DryRun   bool            `json:"dry_run"`
+	Total    int             `json:"total"`
+	Migrated int             `json:"migrated"`
+	Failed   []MigrationFail `json:"failed"`
+}
+
+// MigrationFail adalah satu baris yang gagal dimigrasi beserta alasannya
+type MigrationFail struct {
+	ID     primitive.ObjectID `json:"id"`
+	Field  string             `json:"field"`
+	Value  string             `json:"value"`
+	Reason string             `json:"reason"`
+}
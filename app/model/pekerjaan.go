@@ -12,11 +12,58 @@ type PekerjaanAlumni struct {
     PosisiJabatan       string             `bson:"posisi_jabatan" json:"posisi_jabatan"`
     BidangIndustri      string             `bson:"bidang_industri" json:"bidang_industri"`
     LokasiKerja         string             `bson:"lokasi_kerja" json:"lokasi_kerja"`
-    GajiRange           string             `bson:"gaji_range" json:"gaji_range"`
-    TanggalMulaiKerja   string             `bson:"tanggal_mulai_kerja" json:"tanggal_mulai_kerja"`
-    TanggalSelesaiKerja string             `bson:"tanggal_selesai_kerja" json:"tanggal_selesai_kerja"`
+    Gaji                *Gaji              `bson:"gaji,omitempty" json:"gaji,omitempty"`
+    TanggalMulaiKerja   time.Time          `bson:"tanggal_mulai_kerja" json:"tanggal_mulai_kerja"`
+    TanggalSelesaiKerja *time.Time         `bson:"tanggal_selesai_kerja" json:"tanggal_selesai_kerja"` // nil = masih bekerja
     StatusPekerjaan     string             `bson:"status_pekerjaan" json:"status_pekerjaan"`
     DeskripsiPekerjaan  string             `bson:"deskripsi_pekerjaan" json:"deskripsi_pekerjaan"`
     CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
     UpdatedAt           time.Time          `bson:"updated_at" json:"updated_at"`
+    IsDeleted           *time.Time         `bson:"is_deleted,omitempty" json:"is_deleted,omitempty"`
+}
+
+// Gaji adalah rentang gaji terstruktur. Max 0 berarti tidak ada batas atas
+// (misalnya "> 10 juta").
+type Gaji struct {
+    Min      int64  `bson:"min" json:"min"`
+    Max      int64  `bson:"max" json:"max"`
+    Currency string `bson:"currency" json:"currency"` // kode ISO 4217, misalnya "IDR"
+    Period   string `bson:"period" json:"period"`     // "bulan" atau "tahun"
+}
+
+// PekerjaanInput adalah body create/update pekerjaan. Tanggal diterima sebagai
+// string (ISO atau format Indonesia) lalu diparse menjadi time.Time.
+type PekerjaanInput struct {
+    AlumniID            primitive.ObjectID `json:"alumni_id"`
+    NamaPerusahaan      string             `json:"nama_perusahaan"`
+    PosisiJabatan       string             `json:"posisi_jabatan"`
+    BidangIndustri      string             `json:"bidang_industri"`
+    LokasiKerja         string             `json:"lokasi_kerja"`
+    Gaji                *Gaji              `json:"gaji"`
+    GajiRange           string             `json:"gaji_range"` // format lama, diparse jika gaji kosong
+    TanggalMulaiKerja   string             `json:"tanggal_mulai_kerja"`
+    TanggalSelesaiKerja string             `json:"tanggal_selesai_kerja"` // kosong = masih bekerja
+    StatusPekerjaan     string             `json:"status_pekerjaan"`
+    DeskripsiPekerjaan  string             `json:"deskripsi_pekerjaan"`
+}
+
+// PekerjaanFilter adalah parameter query listing pekerjaan
+type PekerjaanFilter struct {
+    IncludeDeleted bool
+    AktifPada      *time.Time // pekerjaan yang sedang berjalan pada tanggal ini
+    MulaiDari      *time.Time // tanggal_mulai_kerja >= MulaiDari
+    MulaiSampai    *time.Time // tanggal_mulai_kerja <= MulaiSampai
+}
+
+// LegacyTanggalPekerjaan adalah dokumen lama yang tanggalnya masih string
+type LegacyTanggalPekerjaan struct {
+    ID                  primitive.ObjectID `bson:"_id"`
+    TanggalMulaiKerja   interface{}        `bson:"tanggal_mulai_kerja"`
+    TanggalSelesaiKerja interface{}        `bson:"tanggal_selesai_kerja"`
+}
+
+// LegacyGajiPekerjaan adalah dokumen lama yang gajinya masih string gaji_range
+type LegacyGajiPekerjaan struct {
+    ID        primitive.ObjectID `bson:"_id"`
+    GajiRange string             `bson:"gaji_range"`
 }
@@ -0,0 +1,31 @@
+package model
+
+// Jenis hasil pencarian
+const (
+	SearchAlumni    = "alumni"
+	SearchPekerjaan = "pekerjaan"
+)
+
+// AlumniSearchResult adalah alumni yang cocok dengan pencarian teks beserta skor relevansinya
+type AlumniSearchResult struct {
+	Alumni `bson:",inline"`
+	Score  float64 `bson:"score" json:"-"`
+}
+
+// PekerjaanSearchResult adalah pekerjaan yang cocok dengan pencarian teks
+type PekerjaanSearchResult struct {
+	PekerjaanAlumni `bson:",inline"`
+	AlumniNama      string  `bson:"alumni_nama" json:"alumni_nama"`
+	Score           float64 `bson:"score" json:"-"`
+}
+
+// SearchHit adalah satu hasil /api/search. Highlights berisi potongan field
+// yang cocok dengan kata kunci ditandai <em>...</em> (teks lain sudah di-escape).
+type SearchHit struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Score      float64           `json:"score"`
+	Judul      string            `json:"judul"`
+	Data       interface{}       `json:"data"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
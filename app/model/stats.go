@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// StatistikGaji adalah ringkasan gaji bulanan untuk satu kelompok
+// (jurusan, angkatan, atau bidang industri)
+type StatistikGaji struct {
+	Kelompok  interface{}        `json:"kelompok"`
+	Jumlah    int                `json:"jumlah"`
+	Min       float64            `json:"min"`
+	Max       float64            `json:"max"`
+	Median    float64            `json:"median"`
+	Persentil map[string]float64 `json:"persentil"`
+}
+
+// GajiKelompok adalah hasil agregasi mentah: semua gaji bulanan satu kelompok
+type GajiKelompok struct {
+	Kelompok interface{} `bson:"_id"`
+	Nilai    []float64   `bson:"nilai"`
+}
+
+// TingkatKerja adalah tingkat keterserapan kerja alumni untuk satu kelompok
+type TingkatKerja struct {
+	Kelompok       interface{} `bson:"_id" json:"kelompok"`
+	TotalAlumni    int         `bson:"total_alumni" json:"total_alumni"`
+	PernahBekerja  int         `bson:"pernah_bekerja" json:"pernah_bekerja"`
+	BekerjaSaatIni int         `bson:"bekerja_saat_ini" json:"bekerja_saat_ini"`
+	Rate           float64     `bson:"-" json:"rate"`          // pernah_bekerja / total_alumni
+	RateSaatIni    float64     `bson:"-" json:"rate_saat_ini"` // bekerja_saat_ini / total_alumni
+}
+
+// WaktuTungguKelompok adalah hasil agregasi mentah: waktu tunggu (bulan) tiap alumni
+type WaktuTungguKelompok struct {
+	Kelompok interface{} `bson:"_id"`
+	Bulan    []float64   `bson:"bulan"`
+}
+
+// StatistikWaktuTunggu adalah ringkasan waktu dari lulus sampai pekerjaan pertama
+type StatistikWaktuTunggu struct {
+	Kelompok    interface{} `json:"kelompok"`
+	Jumlah      int         `json:"jumlah"`
+	MedianBulan float64     `json:"median_bulan"`
+	RataRata    float64     `json:"rata_rata_bulan"`
+}
+
+// Distribusi adalah jumlah pekerjaan dan alumni untuk satu nilai (perusahaan, industri, lokasi)
+type Distribusi struct {
+	Nama            string `bson:"nama" json:"nama"`
+	JumlahPekerjaan int    `bson:"jumlah_pekerjaan" json:"jumlah_pekerjaan"`
+	JumlahAlumni    int    `bson:"jumlah_alumni" json:"jumlah_alumni"`
+}
+
+// StatsCache adalah hasil statistik yang sudah dihitung dan disimpan di koleksi stats_cache.
+// Data berisi response JSON lengkap agar bisa dikirim ulang tanpa agregasi.
+type StatsCache struct {
+	Key         string            `bson:"_id" json:"key"`
+	Name        string            `bson:"name" json:"name"`
+	Params      map[string]string `bson:"params" json:"params"`
+	Data        string            `bson:"data" json:"-"`
+	GeneratedAt time.Time         `bson:"generated_at" json:"generated_at"`
+	ReadAt      time.Time         `bson:"read_at" json:"read_at"` // dipakai TTL index
+}
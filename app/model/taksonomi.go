@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis taksonomi yang dipakai untuk memvalidasi field pekerjaan
+const (
+	JenisStatusPekerjaan = "status_pekerjaan"
+	JenisBidangIndustri  = "bidang_industri"
+)
+
+// Taksonomi adalah satu istilah baku dalam kosakata terkontrol.
+// Kode disimpan di data pekerjaan; Alias memetakan nilai lama / variasi
+// penulisan ("IT", "Teknologi Informasi") ke kode yang sama.
+type Taksonomi struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Jenis     string             `bson:"jenis" json:"jenis"`
+	Kode      string             `bson:"kode" json:"kode"`
+	Nama      string             `bson:"nama" json:"nama"`
+	Alias     []string           `bson:"alias" json:"alias"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// LegacyTaksonomiPekerjaan adalah nilai status / bidang industri yang tersimpan di pekerjaan
+type LegacyTaksonomiPekerjaan struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	StatusPekerjaan string             `bson:"status_pekerjaan"`
+	BidangIndustri  string             `bson:"bidang_industri"`
+}
@@ -6,13 +6,42 @@ import (
 )
 
 type TrashPekerjaan struct {
-    ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
-    AlumniID       primitive.ObjectID  `bson:"alumni_id" json:"alumni_id"`
-    UserID         *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
-    NamaAlumni     string              `bson:"nama_alumni" json:"nama_alumni"`
-    NamaPerusahaan string              `bson:"nama_perusahaan" json:"nama_perusahaan"`
-    PosisiJabatan  string              `bson:"posisi_jabatan" json:"posisi_jabatan"`
-    BidangIndustri string              `bson:"bidang_industri" json:"bidang_industri"`
-    LokasiKerja    string              `bson:"lokasi_kerja" json:"lokasi_kerja"`
-    IsDeleted      *time.Time          `bson:"is_deleted,omitempty" json:"is_deleted,omitempty"`
+    ID                primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+    AlumniID          primitive.ObjectID  `bson:"alumni_id" json:"alumni_id"`
+    UserID            *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+    DeletedByUsername string              `bson:"deleted_by_username,omitempty" json:"deleted_by_username,omitempty"`
+    NamaAlumni        string              `bson:"nama_alumni" json:"nama_alumni"`
+    NamaPerusahaan    string              `bson:"nama_perusahaan" json:"nama_perusahaan"`
+    PosisiJabatan     string              `bson:"posisi_jabatan" json:"posisi_jabatan"`
+    BidangIndustri    string              `bson:"bidang_industri" json:"bidang_industri"`
+    LokasiKerja       string              `bson:"lokasi_kerja" json:"lokasi_kerja"`
+    IsDeleted         *time.Time          `bson:"is_deleted,omitempty" json:"is_deleted,omitempty"`
+}
+
+// TrashFilter adalah parameter listing trash (pagination, sorting, filter)
+type TrashFilter struct {
+    Page          int
+    Limit         int
+    Order         string              // urutan berdasarkan waktu hapus: "asc" / "desc"
+    DeletedBy     *primitive.ObjectID // hanya data yang dihapus oleh user ini
+    DeletedAfter  *time.Time          // hanya data yang dihapus setelah waktu ini
+    DeletedBefore *time.Time          // hanya data yang dihapus sebelum waktu ini
+}
+
+// BulkTrashRequest adalah body untuk restore / hapus permanen massal.
+// Jika IDs kosong, target diambil dari Filter.
+type BulkTrashRequest struct {
+    IDs    []string `json:"ids"`
+    Filter *struct {
+        DeletedBy     string     `json:"deleted_by"`
+        DeletedAfter  *time.Time `json:"deleted_after"`
+        DeletedBefore *time.Time `json:"deleted_before"`
+    } `json:"filter"`
+}
+
+// BulkTrashResult adalah hasil per item dari operasi massal
+type BulkTrashResult struct {
+    ID     string `json:"id"`
+    Status string `json:"status"` // "restored", "purged", atau "failed"
+    Reason string `json:"reason,omitempty"`
 }
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Status sesi upload bertahap
+const (
+	UploadActive     = "active"
+	UploadAssembling = "assembling"
+	UploadCompleted  = "completed"
+)
+
+// UploadSession adalah upload file besar yang dikirim per potongan (chunk).
+// Potongan disimpan sementara di storage dan digabung saat complete.
+type UploadSession struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	OriginalName string              `json:"original_name" bson:"original_name"`
+	ContentType  string              `json:"content_type" bson:"content_type"`
+	Size         int64               `json:"size" bson:"size"`
+	ChunkSize    int64               `json:"chunk_size" bson:"chunk_size"`
+	TotalChunks  int                 `json:"total_chunks" bson:"total_chunks"`
+	SHA256       string              `json:"sha256" bson:"sha256"`
+	Category     string              `json:"category" bson:"category"`
+	EntityType   string              `json:"entity_type,omitempty" bson:"entity_type,omitempty"`
+	EntityID     *primitive.ObjectID `json:"entity_id,omitempty" bson:"entity_id,omitempty"`
+	UploadedBy   *primitive.ObjectID `json:"uploaded_by,omitempty" bson:"uploaded_by,omitempty"`
+	Received     []int               `json:"received" bson:"received"`
+	Status       string              `json:"status" bson:"status"`
+	FileID       *primitive.ObjectID `json:"file_id,omitempty" bson:"file_id,omitempty"`
+	AssemblingAt *time.Time          `json:"assembling_at,omitempty" bson:"assembling_at,omitempty"` // saat complete mulai menggabung
+	CreatedAt    time.Time           `json:"created_at" bson:"created_at"`
+	ExpiresAt    time.Time           `json:"expires_at" bson:"expires_at"`
+}
+
+// UploadSessionRequest adalah body untuk memulai sesi upload
+type UploadSessionRequest struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	ChunkSize   int64  `json:"chunk_size"`
+	SHA256      string `json:"sha256"`
+	Category    string `json:"category"`
+	EntityType  string `json:"entity_type"`
+	EntityID    string `json:"entity_id"`
+}
+
+// ChunkKey adalah key storage sementara untuk potongan ke-index
+func (u *UploadSession) ChunkKey(index int) string {
+	return "chunks/" + u.ID.Hex() + "/" + strconv.Itoa(index)
+}
@@ -10,6 +10,8 @@ type User struct {
     Username string             `bson:"username" json:"username"`
     Password string             `bson:"password" json:"password"` // hashed
     Role     string             `bson:"role" json:"role"`
+    // Kuota penyimpanan file khusus user ini (byte); kosong = ikut kuota role
+    StorageQuota *int64 `bson:"storage_quota,omitempty" json:"storage_quota,omitempty"`
 }
 
 type LoginRequest struct {
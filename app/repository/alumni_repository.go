@@ -8,17 +8,24 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type IAlumniRepository interface {
 	GetAll(ctx context.Context) ([]model.Alumni, error)
 	GetByID(ctx context.Context, id string) (*model.Alumni, error)
+	GetByNIM(ctx context.Context, nim string) (*model.Alumni, error)
+	UpsertByNIM(ctx context.Context, alumni *model.Alumni, fields []string) (bool, error)
 	Create(ctx context.Context, alumni *model.Alumni) (*model.Alumni, error)
 	Update(ctx context.Context, id string, alumni *model.Alumni) error
 	Delete(ctx context.Context, id string) error
-	GetWithFilter(ctx context.Context, page, limit int, sortBy, order, search string) ([]model.Alumni, int, error)
+	GetWithFilter(ctx context.Context, page, limit int, sortBy, order string, f model.AlumniFilter) ([]model.Alumni, int, error)
+	Export(ctx context.Context, f model.AlumniFilter) (*mongo.Cursor, error)
 	// --- TAMBAHKAN METHOD INI KE INTERFACE ---
 	GetJumlahByAngkatan(ctx context.Context) ([]model.JumlahAngkatan, error)
+	GetByJumlahPekerjaan(ctx context.Context, min, max, page, limit int) ([]model.AlumniJumlahPekerjaan, int, error)
+	GetAllExpanded(ctx context.Context, expand []string) ([]model.AlumniDetail, error)
+	GetByIDExpanded(ctx context.Context, id string, expand []string) (*model.AlumniDetail, error)
 }
 
 type AlumniRepository struct {
@@ -62,6 +69,50 @@ func (r *AlumniRepository) GetByID(ctx context.Context, id string) (*model.Alumn
 	return &alumni, nil
 }
 
+// Ambil alumni berdasarkan NIM
+func (r *AlumniRepository) GetByNIM(ctx context.Context, nim string) (*model.Alumni, error) {
+	var alumni model.Alumni
+	err := r.collection.FindOne(ctx, bson.M{"nim": nim}).Decode(&alumni)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &alumni, nil
+}
+
+// Tambah atau update alumni berdasarkan NIM. Mengembalikan true jika data baru dibuat.
+// Pada data lama hanya field di fields yang ditimpa (misalnya kolom yang ada di file
+// import); field lain hanya diisi saat data baru dibuat.
+func (r *AlumniRepository) UpsertByNIM(ctx context.Context, alumni *model.Alumni, fields []string) (bool, error) {
+	insert := bson.M{
+		"nama":        alumni.Nama,
+		"jurusan":     alumni.Jurusan,
+		"angkatan":    alumni.Angkatan,
+		"tahun_lulus": alumni.TahunLulus,
+		"email":       alumni.Email,
+		"no_telepon":  alumni.NoTelepon,
+		"alamat":      alumni.Alamat,
+		"created_at":  alumni.CreatedAt,
+	}
+	set := bson.M{"updated_at": alumni.UpdatedAt}
+	for _, f := range fields {
+		if v, ok := insert[f]; ok && f != "created_at" {
+			set[f] = v
+			delete(insert, f)
+		}
+	}
+
+	update := bson.M{"$set": set, "$setOnInsert": insert}
+	opts := options.Update().SetUpsert(true)
+	res, err := r.collection.UpdateOne(ctx, bson.M{"nim": alumni.NIM}, update, opts)
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount > 0, nil
+}
+
 // Tambah alumni baru
 func (r *AlumniRepository) Create(ctx context.Context, alumni *model.Alumni) (*model.Alumni, error) {
 	alumni.ID = primitive.NilObjectID
@@ -106,8 +157,37 @@ func (r *AlumniRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
+// alumniMatch membangun filter alumni. prefix dipakai jika dokumen alumni
+// berada di field hasil $lookup (misalnya "alumni.").
+func alumniMatch(f model.AlumniFilter, prefix string) bson.M {
+	filter := bson.M{}
+	if f.Search != "" {
+		filter["$or"] = []bson.M{
+			{prefix + "nama": bson.M{"$regex": f.Search, "$options": "i"}},
+			{prefix + "jurusan": bson.M{"$regex": f.Search, "$options": "i"}},
+		}
+	}
+	if f.Jurusan != "" {
+		filter[prefix+"jurusan"] = f.Jurusan
+	}
+	if f.Angkatan != 0 {
+		filter[prefix+"angkatan"] = f.Angkatan
+	}
+	if f.TahunLulus != 0 {
+		filter[prefix+"tahun_lulus"] = f.TahunLulus
+	}
+	return filter
+}
+
+// Export membuka cursor alumni sesuai filter untuk dialirkan tanpa dimuat
+// sekaligus ke memori. Pemanggil wajib menutup cursor.
+func (r *AlumniRepository) Export(ctx context.Context, f model.AlumniFilter) (*mongo.Cursor, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "nim", Value: 1}}).SetBatchSize(500)
+	return r.collection.Find(ctx, alumniMatch(f, ""), opts)
+}
+
 // GetWithFilter - Mendapatkan data alumni dengan pagination, sorting, dan search
-func (r *AlumniRepository) GetWithFilter(ctx context.Context, page, limit int, sortBy, order, search string) ([]model.Alumni, int, error) {
+func (r *AlumniRepository) GetWithFilter(ctx context.Context, page, limit int, sortBy, order string, f model.AlumniFilter) ([]model.Alumni, int, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -117,13 +197,7 @@ func (r *AlumniRepository) GetWithFilter(ctx context.Context, page, limit int, s
 	skip := (page - 1) * limit
 
 	// Filter pencarian
-	filter := bson.M{}
-	if search != "" {
-		filter["$or"] = []bson.M{
-			{"nama": bson.M{"$regex": search, "$options": "i"}},
-			{"jurusan": bson.M{"$regex": search, "$options": "i"}},
-		}
-	}
+	filter := alumniMatch(f, "")
 
 	// Sorting
 	sortOrder := 1
@@ -183,4 +257,150 @@ func (r *AlumniRepository) GetJumlahByAngkatan(ctx context.Context) ([]model.Jum
 	}
 
 	return results, nil
-}
\ No newline at end of file
+}
+
+// GetByJumlahPekerjaan - Alumni dengan jumlah pekerjaan aktif (bukan di trash)
+// antara min dan max (max 0 = tanpa batas atas), urut dari yang terbanyak
+func (r *AlumniRepository) GetByJumlahPekerjaan(ctx context.Context, min, max, page, limit int) ([]model.AlumniJumlahPekerjaan, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	pipeline := jumlahPekerjaanPipeline(min, max, (page-1)*limit, limit)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var facet []struct {
+		Data  []model.AlumniJumlahPekerjaan `bson:"data"`
+		Total []struct {
+			N int `bson:"n"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &facet); err != nil {
+		return nil, 0, err
+	}
+
+	result := []model.AlumniJumlahPekerjaan{}
+	total := 0
+	if len(facet) > 0 {
+		if facet[0].Data != nil {
+			result = facet[0].Data
+		}
+		if len(facet[0].Total) > 0 {
+			total = facet[0].Total[0].N
+		}
+	}
+	return result, total, nil
+}
+
+// jumlahPekerjaanPipeline menghitung pekerjaan aktif per alumni lalu memfilter
+// berdasarkan min / max. Hasil $facet: data (satu halaman) dan total.
+func jumlahPekerjaanPipeline(min, max, skip, limit int) mongo.Pipeline {
+	jumlah := bson.M{"$gte": min}
+	if max > 0 {
+		jumlah["$lte"] = max
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "pekerjaan_alumni"},
+			{Key: "let", Value: bson.D{{Key: "aid", Value: "$_id"}}},
+			{Key: "pipeline", Value: mongo.Pipeline{
+				{{Key: "$match", Value: bson.D{
+					{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$alumni_id", "$$aid"}}}},
+					{Key: "is_deleted", Value: nil},
+				}}},
+				{{Key: "$count", Value: "n"}},
+			}},
+			{Key: "as", Value: "pekerjaan"},
+		}}},
+		{{Key: "$addFields", Value: bson.D{{Key: "jumlah_pekerjaan", Value: bson.D{{Key: "$ifNull", Value: bson.A{
+			bson.D{{Key: "$arrayElemAt", Value: bson.A{"$pekerjaan.n", 0}}}, 0,
+		}}}}}}},
+		{{Key: "$match", Value: bson.M{"jumlah_pekerjaan": jumlah}}},
+		{{Key: "$facet", Value: bson.D{
+			{Key: "data", Value: mongo.Pipeline{
+				{{Key: "$sort", Value: bson.D{{Key: "jumlah_pekerjaan", Value: -1}, {Key: "nama", Value: 1}, {Key: "_id", Value: 1}}}},
+				{{Key: "$skip", Value: skip}},
+				{{Key: "$limit", Value: limit}},
+				{{Key: "$project", Value: bson.D{
+					{Key: "nim", Value: 1},
+					{Key: "nama", Value: 1},
+					{Key: "jumlah_pekerjaan", Value: 1},
+				}}},
+			}},
+			{Key: "total", Value: mongo.Pipeline{{{Key: "$count", Value: "n"}}}},
+		}}},
+	}
+}
+
+// expandStages membangun stage $lookup untuk relasi yang diminta lewat ?expand=
+func expandStages(expand []string) mongo.Pipeline {
+	var stages mongo.Pipeline
+	for _, e := range expand {
+		switch e {
+		case "pekerjaan":
+			// Riwayat karier aktif (bukan di trash), urut dari pekerjaan pertama
+			stages = append(stages, bson.D{{Key: "$lookup", Value: bson.D{
+				{Key: "from", Value: "pekerjaan_alumni"},
+				{Key: "let", Value: bson.D{{Key: "aid", Value: "$_id"}}},
+				{Key: "pipeline", Value: mongo.Pipeline{
+					{{Key: "$match", Value: bson.D{
+						{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$alumni_id", "$$aid"}}}},
+						{Key: "is_deleted", Value: nil},
+					}}},
+					{{Key: "$sort", Value: bson.D{{Key: "tanggal_mulai_kerja", Value: 1}, {Key: "_id", Value: 1}}}},
+				}},
+				{Key: "as", Value: "pekerjaan"},
+			}}})
+		}
+	}
+	return stages
+}
+
+// GetAllExpanded - Semua alumni beserta relasi yang diminta
+func (r *AlumniRepository) GetAllExpanded(ctx context.Context, expand []string) ([]model.AlumniDetail, error) {
+	cursor, err := r.collection.Aggregate(ctx, expandStages(expand))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var list []model.AlumniDetail
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetByIDExpanded - Satu alumni beserta relasi yang diminta
+func (r *AlumniRepository) GetByIDExpanded(ctx context.Context, id string, expand []string) (*model.AlumniDetail, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("ID tidak valid")
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"_id": objID}}}}
+	pipeline = append(pipeline, expandStages(expand)...)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var list []model.AlumniDetail
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return &list[0], nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"praktikummongo/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// stageValue mengambil isi stage pertama dengan nama op, misalnya "$match"
+func stageValue(t *testing.T, p mongo.Pipeline, op string) interface{} {
+	t.Helper()
+	for _, stage := range p {
+		for _, e := range stage {
+			if e.Key == op {
+				return e.Value
+			}
+		}
+	}
+	t.Fatalf("stage %s tidak ada di pipeline", op)
+	return nil
+}
+
+func field(t *testing.T, d bson.D, key string) interface{} {
+	t.Helper()
+	for _, e := range d {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	t.Fatalf("field %s tidak ada di %v", key, d)
+	return nil
+}
+
+func TestJumlahPekerjaanPipelineBatas(t *testing.T) {
+	tests := []struct {
+		min, max int
+		want     bson.M
+	}{
+		{2, 0, bson.M{"$gte": 2}},
+		{0, 0, bson.M{"$gte": 0}},
+		{1, 3, bson.M{"$gte": 1, "$lte": 3}},
+	}
+	for _, tt := range tests {
+		match := stageValue(t, jumlahPekerjaanPipeline(tt.min, tt.max, 0, 10), "$match").(bson.M)
+		got := match["jumlah_pekerjaan"].(bson.M)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("min=%d max=%d: filter %v, want %v", tt.min, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestJumlahPekerjaanPipelineAbaikanTrash(t *testing.T) {
+	lookup := stageValue(t, jumlahPekerjaanPipeline(2, 0, 0, 10), "$lookup").(bson.D)
+	if from := field(t, lookup, "from"); from != "pekerjaan_alumni" {
+		t.Fatalf("lookup from = %v", from)
+	}
+	inner := field(t, lookup, "pipeline").(mongo.Pipeline)
+	match := stageValue(t, inner, "$match").(bson.D)
+	if v := field(t, match, "is_deleted"); v != nil {
+		t.Errorf("is_deleted = %v, want nil (hanya pekerjaan aktif)", v)
+	}
+}
+
+func TestJumlahPekerjaanPipelineHalaman(t *testing.T) {
+	facet := stageValue(t, jumlahPekerjaanPipeline(2, 0, 20, 5), "$facet").(bson.D)
+	data := field(t, facet, "data").(mongo.Pipeline)
+
+	if skip := stageValue(t, data, "$skip"); skip != 20 {
+		t.Errorf("$skip = %v, want 20", skip)
+	}
+	if limit := stageValue(t, data, "$limit"); limit != 5 {
+		t.Errorf("$limit = %v, want 5", limit)
+	}
+	project := stageValue(t, data, "$project").(bson.D)
+	for _, key := range []string{"nim", "nama", "jumlah_pekerjaan"} {
+		if v := field(t, project, key); v != 1 {
+			t.Errorf("$project %s = %v, want 1", key, v)
+		}
+	}
+	for _, e := range project {
+		if e.Key == "_id" && e.Value == 0 {
+			t.Error("_id tidak boleh dibuang dari hasil")
+		}
+	}
+}
+
+// testDB membuka database sementara di MONGO_URI; test dilewati jika server tidak ada
+func testDB(t *testing.T) *mongo.Database {
+	t.Helper()
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI tidak diatur")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerSelectionTimeout(2*time.Second))
+	if err != nil {
+		t.Skip("MongoDB tidak tersedia:", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		t.Skip("MongoDB tidak tersedia:", err)
+	}
+
+	db := client.Database(fmt.Sprintf("alumni_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+	return db
+}
+
+func TestGetByJumlahPekerjaan(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewAlumniRepository(db)
+
+	// A: 3 aktif; B: 2 aktif + 2 trash; C: 1 aktif + 3 trash; D: tanpa pekerjaan
+	jumlah := []struct {
+		nim, nama    string
+		aktif, trash int
+	}{
+		{"A001", "Andi", 3, 0},
+		{"B002", "Budi", 2, 2},
+		{"C003", "Citra", 1, 3},
+		{"D004", "Dewi", 0, 0},
+	}
+	ids := map[string]primitive.ObjectID{}
+	for _, j := range jumlah {
+		a, err := repo.Create(ctx, &model.Alumni{NIM: j.nim, Nama: j.nama})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[j.nim] = a.ID
+
+		deleted := time.Now()
+		for i := 0; i < j.aktif+j.trash; i++ {
+			p := bson.M{"alumni_id": a.ID, "nama_perusahaan": fmt.Sprintf("PT %d", i)}
+			if i >= j.aktif {
+				p["is_deleted"] = deleted
+			}
+			if _, err := db.Collection("pekerjaan_alumni").InsertOne(ctx, p); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("batas min tanpa max", func(t *testing.T) {
+		items, total, err := repo.GetByJumlahPekerjaan(ctx, 2, 0, 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 2 || len(items) != 2 {
+			t.Fatalf("total=%d len=%d, want 2", total, len(items))
+		}
+		want := model.AlumniJumlahPekerjaan{ID: ids["A001"], NIM: "A001", Nama: "Andi", JumlahPekerjaan: 3}
+		if items[0] != want {
+			t.Errorf("items[0] = %+v, want %+v", items[0], want)
+		}
+		if items[1].NIM != "B002" || items[1].JumlahPekerjaan != 2 {
+			t.Errorf("items[1] = %+v, want B002 dengan 2 pekerjaan (trash tidak dihitung)", items[1])
+		}
+	})
+
+	t.Run("batas min dan max", func(t *testing.T) {
+		items, total, err := repo.GetByJumlahPekerjaan(ctx, 1, 1, 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 1 || len(items) != 1 || items[0].NIM != "C003" {
+			t.Fatalf("got total=%d items=%+v, want hanya C003", total, items)
+		}
+	})
+
+	t.Run("halaman", func(t *testing.T) {
+		items, total, err := repo.GetByJumlahPekerjaan(ctx, 0, 0, 2, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 4 {
+			t.Errorf("total = %d, want 4", total)
+		}
+		if len(items) != 1 || items[0].NIM != "D004" || items[0].JumlahPekerjaan != 0 {
+			t.Errorf("halaman 2 = %+v, want hanya D004 dengan 0 pekerjaan", items)
+		}
+	})
+
+	t.Run("halaman di luar data", func(t *testing.T) {
+		items, total, err := repo.GetByJumlahPekerjaan(ctx, 0, 0, 5, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 4 || items == nil || len(items) != 0 {
+			t.Errorf("got total=%d items=%v, want total 4 dan slice kosong", total, items)
+		}
+	})
+}
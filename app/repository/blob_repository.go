@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"praktikummongo/app/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IBlobRepository interface {
+	Acquire(ctx context.Context, blob *model.Blob) (*model.Blob, error)
+	Release(ctx context.Context, hash string) (*model.Blob, error)
+	Stats(ctx context.Context) (*model.DedupStats, error)
+	GetAll(ctx context.Context) ([]model.Blob, error)
+	FixRefCount(ctx context.Context, hash string, from, to int) (bool, error)
+	Rekey(ctx context.Context, hash, key string, variants map[string]model.FileVariant) error
+}
+
+type BlobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewBlobRepository(db *mongo.Database) IBlobRepository {
+	return &BlobRepository{collection: db.Collection("blobs")}
+}
+
+// Acquire menambah referensi ke blob dengan hash blob.ID. Jika belum ada, blob
+// disimpan apa adanya. Yang dikembalikan adalah blob di database: bila
+// StorageKey-nya berbeda, isi yang baru diupload adalah duplikat.
+func (r *BlobRepository) Acquire(ctx context.Context, blob *model.Blob) (*model.Blob, error) {
+	update := bson.M{
+		"$inc": bson.M{"ref_count": 1},
+		"$setOnInsert": bson.M{
+			"storage_key": blob.StorageKey,
+			"size":        blob.Size,
+			"file_type":   blob.FileType,
+			"variants":    blob.Variants,
+			"created_at":  time.Now(),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var saved model.Blob
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": blob.ID}, update, opts).Decode(&saved)
+	if mongo.IsDuplicateKeyError(err) {
+		// Dua upsert bersamaan untuk hash yang sama; yang kalah cukup diulang
+		err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": blob.ID}, update, opts).Decode(&saved)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// Release mengurangi referensi blob. Jika referensi habis, dokumen blob
+// dihapus dan dikembalikan agar isinya bisa dihapus dari storage; selain itu nil.
+func (r *BlobRepository) Release(ctx context.Context, hash string) (*model.Blob, error) {
+	var blob model.Blob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": hash},
+		bson.M{"$inc": bson.M{"ref_count": -1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&blob)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil || blob.RefCount > 0 {
+		return nil, err
+	}
+
+	// Filter ref_count mencegah penghapusan jika ada Acquire di antaranya
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": hash, "ref_count": bson.M{"$lte": 0}})
+	if err != nil || res.DeletedCount == 0 {
+		return nil, err
+	}
+	return &blob, nil
+}
+
+// Stats menghitung ruang yang dihemat: logical_bytes adalah total ukuran bila
+// setiap referensi disimpan terpisah, stored_bytes yang benar-benar tersimpan
+func (r *BlobRepository) Stats(ctx context.Context) (*model.DedupStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":            nil,
+			"blobs":          bson.M{"$sum": 1},
+			"references":     bson.M{"$sum": "$ref_count"},
+			"stored_bytes":   bson.M{"$sum": "$size"},
+			"logical_bytes":  bson.M{"$sum": bson.M{"$multiply": bson.A{"$size", "$ref_count"}}},
+			"duplicate_refs": bson.M{"$sum": bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{"$ref_count", 1}}}}},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"saved_bytes": bson.M{"$subtract": bson.A{"$logical_bytes", "$stored_bytes"}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	stats := &model.DedupStats{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(stats); err != nil {
+			return nil, err
+		}
+	}
+	return stats, cursor.Err()
+}
+
+// Ambil semua blob
+func (r *BlobRepository) GetAll(ctx context.Context) ([]model.Blob, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blobs := []model.Blob{}
+	if err := cursor.All(ctx, &blobs); err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// FixRefCount mengubah ref_count dari nilai from ke to; to 0 berarti dokumen blob
+// dihapus. Tidak mengubah apa pun (false) jika ref_count sudah berubah sejak dibaca.
+func (r *BlobRepository) FixRefCount(ctx context.Context, hash string, from, to int) (bool, error) {
+	filter := bson.M{"_id": hash, "ref_count": from}
+	if to <= 0 {
+		res, err := r.collection.DeleteOne(ctx, filter)
+		if err != nil {
+			return false, err
+		}
+		return res.DeletedCount > 0, nil
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"ref_count": to}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// Rekey memindahkan blob ke key baru, misalnya setelah isinya dikarantina
+func (r *BlobRepository) Rekey(ctx context.Context, hash, key string, variants map[string]model.FileVariant) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": hash}, bson.M{"$set": bson.M{
+		"storage_key": key,
+		"variants":    variants,
+	}})
+	return err
+}
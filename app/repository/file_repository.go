@@ -3,17 +3,33 @@
 	import (
 		"context"
 		"praktikummongo/app/model" // Sesuaikan dengan nama modul Anda
+		"regexp"
+		"strings"
 		"time"
 
 		"go.mongodb.org/mongo-driver/bson"
 		"go.mongodb.org/mongo-driver/bson/primitive"
 		"go.mongodb.org/mongo-driver/mongo"
+		"go.mongodb.org/mongo-driver/mongo/options"
 	)
 
 	type FileRepository interface {
 		Create(file *model.File) error
 		FindAll() ([]model.File, error)
+		Find(f model.FileFilter) ([]model.File, int64, error)
+		Usage(uploadedBy primitive.ObjectID) (*model.StorageUsage, error)
+		UsageByUser() ([]model.StorageUsage, error)
 		FindByID(id string) (*model.File, error)
+		FindByEntity(entityType string, entityID primitive.ObjectID) ([]model.File, error)
+		FindImagesWithoutVariants() ([]model.File, error)
+		FindWithoutHash() ([]model.File, error)
+		CountByHash(hash string) (int64, error)
+		FindByScanStatus(status string) ([]model.File, error)
+		CountUnscanned() (int64, error)
+		MarkUnscannedPending() (int64, error)
+		SetScanResult(id primitive.ObjectID, status, result string) error
+		Quarantine(key, newKey string, variants map[string]model.FileVariant, result string) error
+		Update(file *model.File) error
 		Delete(id string) error
 	}
 
@@ -61,6 +77,138 @@
 		return files, nil
 	}
 
+	func fileFilter(f model.FileFilter) bson.M {
+		filter := bson.M{}
+		if f.UploadedBy != nil {
+			filter["uploaded_by"] = *f.UploadedBy
+		}
+		if f.FileType != "" {
+			if strings.Contains(f.FileType, "/") {
+				filter["file_type"] = f.FileType
+			} else {
+				filter["file_type"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.FileType) + "/"}
+			}
+		}
+		if f.Category != "" {
+			filter["category"] = f.Category
+		}
+		if f.EntityType != "" {
+			filter["entity_type"] = f.EntityType
+		}
+		if f.EntityID != nil {
+			filter["entity_id"] = *f.EntityID
+		}
+		if f.ScanStatus != "" {
+			filter["scan_status"] = f.ScanStatus
+		}
+		uploadedAt := bson.M{}
+		if f.UploadedAfter != nil {
+			uploadedAt["$gte"] = *f.UploadedAfter
+		}
+		if f.UploadedBefore != nil {
+			uploadedAt["$lt"] = *f.UploadedBefore
+		}
+		if len(uploadedAt) > 0 {
+			filter["uploaded_at"] = uploadedAt
+		}
+		return filter
+	}
+
+	// Find mengambil file sesuai filter dengan pagination, terbaru dulu
+	func (r *fileRepository) Find(f model.FileFilter) ([]model.File, int64, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if f.Page < 1 {
+			f.Page = 1
+		}
+		if f.Limit <= 0 {
+			f.Limit = 10
+		}
+
+		filter := fileFilter(f)
+		total, err := r.collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{Key: "uploaded_at", Value: -1}, {Key: "_id", Value: -1}}).
+			SetSkip(int64((f.Page - 1) * f.Limit)).
+			SetLimit(int64(f.Limit))
+		cursor, err := r.collection.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer cursor.Close(ctx)
+
+		files := []model.File{}
+		if err = cursor.All(ctx, &files); err != nil {
+			return nil, 0, err
+		}
+
+		return files, total, nil
+	}
+
+	// Usage menjumlahkan jumlah dan ukuran file yang diupload satu user
+	func (r *fileRepository) Usage(uploadedBy primitive.ObjectID) (*model.StorageUsage, error) {
+		usage, err := r.usage(bson.M{"uploaded_by": uploadedBy}, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(usage) == 0 {
+			return &model.StorageUsage{UploadedBy: &uploadedBy}, nil
+		}
+		return &usage[0], nil
+	}
+
+	// UsageByUser menjumlahkan pemakaian semua user, terbesar dulu
+	func (r *fileRepository) UsageByUser() ([]model.StorageUsage, error) {
+		return r.usage(bson.M{}, true)
+	}
+
+	func (r *fileRepository) usage(match bson.M, withUsername bool) ([]model.StorageUsage, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: match}},
+			{{Key: "$group", Value: bson.M{
+				"_id":   "$uploaded_by",
+				"files": bson.M{"$sum": 1},
+				"bytes": bson.M{"$sum": "$file_size"},
+			}}},
+			{{Key: "$sort", Value: bson.D{{Key: "bytes", Value: -1}}}},
+		}
+		if withUsername {
+			pipeline = append(pipeline,
+				bson.D{{Key: "$lookup", Value: bson.M{
+					"from":         "users",
+					"localField":   "_id",
+					"foreignField": "_id",
+					"as":           "user",
+				}}},
+				bson.D{{Key: "$addFields", Value: bson.M{
+					"username": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$user.username", 0}}, ""}},
+				}}},
+				bson.D{{Key: "$project", Value: bson.M{"user": 0}}},
+			)
+		}
+
+		cursor, err := r.collection.Aggregate(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		usage := []model.StorageUsage{}
+		if err = cursor.All(ctx, &usage); err != nil {
+			return nil, err
+		}
+
+		return usage, nil
+	}
+
 	func (r *fileRepository) FindByID(id string) (*model.File, error) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -79,6 +227,171 @@
 		return &file, nil
 	}
 
+	// FindByEntity mengambil file milik satu alumni / pekerjaan, terbaru dulu
+	func (r *fileRepository) FindByEntity(entityType string, entityID primitive.ObjectID) ([]model.File, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "uploaded_at", Value: -1}})
+		cursor, err := r.collection.Find(ctx, bson.M{"entity_type": entityType, "entity_id": entityID}, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		files := []model.File{}
+		if err = cursor.All(ctx, &files); err != nil {
+			return nil, err
+		}
+
+		return files, nil
+	}
+
+	// FindImagesWithoutVariants mengambil gambar lama yang belum punya thumbnail
+	func (r *fileRepository) FindImagesWithoutVariants() ([]model.File, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		filter := bson.M{
+			"file_type": bson.M{"$in": bson.A{"image/jpeg", "image/jpg", "image/png"}},
+			"variants":  bson.M{"$exists": false},
+			// File yang sudah jadi blob bisa dipakai bersama, jadi tidak ditulis ulang
+			"sha256": bson.M{"$exists": false},
+		}
+		cursor, err := r.collection.Find(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		files := []model.File{}
+		if err = cursor.All(ctx, &files); err != nil {
+			return nil, err
+		}
+
+		return files, nil
+	}
+
+	// FindWithoutHash mengambil file lama yang belum tercatat di blob deduplikasi
+	func (r *fileRepository) FindWithoutHash() ([]model.File, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		cursor, err := r.collection.Find(ctx, bson.M{"sha256": bson.M{"$exists": false}})
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		files := []model.File{}
+		if err = cursor.All(ctx, &files); err != nil {
+			return nil, err
+		}
+
+		return files, nil
+	}
+
+	// CountByHash menghitung file yang menunjuk blob dengan hash tersebut
+	func (r *fileRepository) CountByHash(hash string) (int64, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		return r.collection.CountDocuments(ctx, bson.M{"sha256": hash})
+	}
+
+	// FindByScanStatus mengambil file dengan status pemindaian tertentu, terlama dulu
+	func (r *fileRepository) FindByScanStatus(status string) ([]model.File, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "uploaded_at", Value: 1}})
+		cursor, err := r.collection.Find(ctx, bson.M{"scan_status": status}, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cursor.Close(ctx)
+
+		files := []model.File{}
+		if err = cursor.All(ctx, &files); err != nil {
+			return nil, err
+		}
+
+		return files, nil
+	}
+
+	// unscannedFilter mencocokkan file lama dari sebelum ada pemindaian (scan_status kosong)
+	func unscannedFilter() bson.M {
+		return bson.M{"$or": []bson.M{
+			{"scan_status": bson.M{"$exists": false}},
+			{"scan_status": ""},
+		}}
+	}
+
+	// CountUnscanned menghitung file lama yang belum pernah dipindai
+	func (r *fileRepository) CountUnscanned() (int64, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		return r.collection.CountDocuments(ctx, unscannedFilter())
+	}
+
+	// MarkUnscannedPending menandai file lama yang belum pernah dipindai sebagai pending_scan
+	func (r *fileRepository) MarkUnscannedPending() (int64, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		result, err := r.collection.UpdateMany(ctx, unscannedFilter(), bson.M{"$set": bson.M{"scan_status": model.ScanPending}})
+		if err != nil {
+			return 0, err
+		}
+		return result.ModifiedCount, nil
+	}
+
+	// SetScanResult mencatat hasil pemindaian satu file
+	func (r *fileRepository) SetScanResult(id primitive.ObjectID, status, result string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		set := bson.M{"scan_status": status}
+		unset := bson.M{}
+		if status == model.ScanPending {
+			unset["scanned_at"] = ""
+		} else {
+			set["scanned_at"] = time.Now()
+		}
+		if result != "" {
+			set["scan_result"] = result
+		} else {
+			unset["scan_result"] = ""
+		}
+		_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set, "$unset": unset})
+		return err
+	}
+
+	// Quarantine menandai semua file yang memakai isi key sebagai infected dan
+	// mengarahkannya ke key karantina. File duplikat ikut karena isinya sama.
+	func (r *fileRepository) Quarantine(key, newKey string, variants map[string]model.FileVariant, result string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := r.collection.UpdateMany(ctx, bson.M{"storage_key": key}, bson.M{"$set": bson.M{
+			"storage_key": newKey,
+			"variants":    variants,
+			"scan_status": model.ScanInfected,
+			"scan_result": result,
+			"scanned_at":  time.Now(),
+		}})
+		return err
+	}
+
+	func (r *fileRepository) Update(file *model.File) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": file.ID}, file)
+		return err
+	}
+
 	func (r *fileRepository) Delete(id string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"praktikummongo/app/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IImportJobRepository interface {
+	Create(ctx context.Context, job *model.ImportJob) (*model.ImportJob, error)
+	Update(ctx context.Context, job *model.ImportJob) error
+	GetByID(ctx context.Context, id string) (*model.ImportJob, error)
+	FailRunning(ctx context.Context, reason string) (int64, error)
+}
+
+type ImportJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewImportJobRepository(db *mongo.Database) IImportJobRepository {
+	return &ImportJobRepository{collection: db.Collection("import_jobs")}
+}
+
+// Simpan job baru
+func (r *ImportJobRepository) Create(ctx context.Context, job *model.ImportJob) (*model.ImportJob, error) {
+	job.ID = primitive.NilObjectID
+	res, err := r.collection.InsertOne(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	job.ID = res.InsertedID.(primitive.ObjectID)
+	return job, nil
+}
+
+// Simpan progres / hasil job
+func (r *ImportJobRepository) Update(ctx context.Context, job *model.ImportJob) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job)
+	return err
+}
+
+// Ambil job berdasarkan ID
+func (r *ImportJobRepository) GetByID(ctx context.Context, id string) (*model.ImportJob, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("ID tidak valid")
+	}
+
+	var job model.ImportJob
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Tandai job yang belum selesai (pending / running) sebagai gagal. Dipanggil saat
+// start: job background tidak dilanjutkan setelah server berhenti.
+func (r *ImportJobRepository) FailRunning(ctx context.Context, reason string) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx,
+		bson.M{"status": bson.M{"$in": bson.A{model.JobPending, model.JobRunning}}},
+		bson.M{"$set": bson.M{
+			"status":      model.JobFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
@@ -9,41 +9,94 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type IPekerjaanRepository interface {
-	GetAll(ctx context.Context) ([]model.PekerjaanAlumni, error)
-	GetByID(ctx context.Context, id string) (*model.PekerjaanAlumni, error)
+	GetAll(ctx context.Context, f model.PekerjaanFilter) ([]model.PekerjaanAlumni, error)
+	Export(ctx context.Context, f model.PekerjaanFilter) (*mongo.Cursor, error)
+	GetByID(ctx context.Context, id string, includeDeleted bool) (*model.PekerjaanAlumni, error)
 	GetByAlumniID(ctx context.Context, alumniID string) ([]model.PekerjaanAlumni, error)
 	GetByAlumniUserID(ctx context.Context, userID string) ([]model.PekerjaanAlumni, error)
 	Create(ctx context.Context, pekerjaan *model.PekerjaanAlumni) (*model.PekerjaanAlumni, error)
-	Update(ctx context.Context, id string, pekerjaan *model.PekerjaanAlumni) error
+	Update(ctx context.Context, id string, pekerjaan *model.PekerjaanAlumni) (bool, error)
 	Delete(ctx context.Context, id string) error
 	SoftDelete(ctx context.Context, id string, userID string) error
-	GetTrash(ctx context.Context, role string, userID *primitive.ObjectID) ([]model.TrashPekerjaan, error)
+	GetTrash(ctx context.Context, role string, userID *primitive.ObjectID, f model.TrashFilter) ([]model.TrashPekerjaan, int, error)
 	Restore(ctx context.Context, id string) error
 	HardDelete(ctx context.Context, id string) error
 	GetOwnerID(ctx context.Context, pekerjaanID string) (*primitive.ObjectID, error)
 	GetOwnerAndDeleteStatus(ctx context.Context, id string) (*primitive.ObjectID, *bool, error)
+	GetTrashIDs(ctx context.Context, role string, userID *primitive.ObjectID, f model.TrashFilter) ([]primitive.ObjectID, error)
+	GetExpiredTrash(ctx context.Context, cutoff time.Time) ([]model.TrashPekerjaan, error)
+	PurgeTrash(ctx context.Context, ids []primitive.ObjectID, cutoff time.Time) (int64, error)
+	GetLegacyTanggal(ctx context.Context) ([]model.LegacyTanggalPekerjaan, error)
+	SetTanggal(ctx context.Context, id primitive.ObjectID, mulai time.Time, selesai *time.Time) error
+	GetLegacyGaji(ctx context.Context) ([]model.LegacyGajiPekerjaan, error)
+	SetGaji(ctx context.Context, id primitive.ObjectID, gaji *model.Gaji) error
+	GetTaksonomiValues(ctx context.Context) ([]model.LegacyTaksonomiPekerjaan, error)
+	SetTaksonomi(ctx context.Context, id primitive.ObjectID, status, bidang string) error
+	CountByTaksonomi(ctx context.Context, jenis, kode string) (int64, error)
+	ReplaceTaksonomi(ctx context.Context, jenis, from, to string) (int64, error)
 }
 
 type PekerjaanRepository struct {
 	collection *mongo.Collection
 	alumniColl *mongo.Collection
+	userColl   *mongo.Collection
 }
 
 func NewPekerjaanRepository(db *mongo.Database) IPekerjaanRepository {
 	return &PekerjaanRepository{
 		collection: db.Collection("pekerjaan_alumni"),
 		alumniColl: db.Collection("alumni"),
+		userColl:   db.Collection("users"),
 	}
 }
 
-// Ambil semua pekerjaan aktif
+// Filter dasar pekerjaan: data di trash disembunyikan kecuali includeDeleted
+func activeFilter(includeDeleted bool) bson.M {
+	if includeDeleted {
+		return bson.M{}
+	}
+	// Dokumen aktif adalah yang 'is_deleted' tidak ada (null)
+	return bson.M{"is_deleted": nil}
+}
+
+// pekerjaanFilter membentuk filter dari PekerjaanFilter (status trash dan periode kerja)
+func pekerjaanFilter(f model.PekerjaanFilter) bson.M {
+	filter := activeFilter(f.IncludeDeleted)
+
+	mulai := bson.M{}
+	if f.MulaiDari != nil {
+		mulai["$gte"] = *f.MulaiDari
+	}
+	if f.MulaiSampai != nil {
+		mulai["$lte"] = *f.MulaiSampai
+	}
+	if f.AktifPada != nil {
+		// Aktif pada X: mulai <= X dan (belum selesai atau selesai >= X)
+		if cur, ok := mulai["$lte"].(time.Time); !ok || f.AktifPada.Before(cur) {
+			mulai["$lte"] = *f.AktifPada
+		}
+		filter["$or"] = bson.A{
+			bson.M{"tanggal_selesai_kerja": nil},
+			bson.M{"tanggal_selesai_kerja": bson.M{"$gte": *f.AktifPada}},
+		}
+	}
+	if len(mulai) > 0 {
+		filter["tanggal_mulai_kerja"] = mulai
+	}
+	return filter
+}
+
+// Ambil semua pekerjaan aktif, bisa difilter berdasarkan periode kerja
 // (FIXED: Mencari yang is_deleted TIDAK ADA atau NIL)
-func (r *PekerjaanRepository) GetAll(ctx context.Context) ([]model.PekerjaanAlumni, error) {
-	// Temukan dokumen di mana 'is_deleted' tidak ada (null)
-	cursor, err := r.collection.Find(ctx, bson.M{"is_deleted": nil})
+func (r *PekerjaanRepository) GetAll(ctx context.Context, f model.PekerjaanFilter) ([]model.PekerjaanAlumni, error) {
+	filter := pekerjaanFilter(f)
+
+	opts := options.Find().SetSort(bson.D{{Key: "tanggal_mulai_kerja", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -56,15 +109,27 @@ func (r *PekerjaanRepository) GetAll(ctx context.Context) ([]model.PekerjaanAlum
 	return result, nil
 }
 
+// Export membuka cursor pekerjaan sesuai filter untuk dialirkan tanpa dimuat
+// sekaligus ke memori. Pemanggil wajib menutup cursor.
+func (r *PekerjaanRepository) Export(ctx context.Context, f model.PekerjaanFilter) (*mongo.Cursor, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "tanggal_mulai_kerja", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(500)
+	return r.collection.Find(ctx, pekerjaanFilter(f), opts)
+}
+
 // Ambil pekerjaan berdasarkan ID
-func (r *PekerjaanRepository) GetByID(ctx context.Context, id string) (*model.PekerjaanAlumni, error) {
+func (r *PekerjaanRepository) GetByID(ctx context.Context, id string, includeDeleted bool) (*model.PekerjaanAlumni, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, errors.New("ID tidak valid")
 	}
 
+	filter := activeFilter(includeDeleted)
+	filter["_id"] = objID
+
 	var pekerjaan model.PekerjaanAlumni
-	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&pekerjaan)
+	err = r.collection.FindOne(ctx, filter).Decode(&pekerjaan)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
@@ -84,13 +149,18 @@ func (r *PekerjaanRepository) GetByAlumniID(ctx context.Context, alumniID string
 		return nil, errors.New("Alumni ID tidak valid")
 	}
 
-	cursor, err := r.collection.Find(ctx, bson.M{"alumni_id": alumniObjID})
+	filter := activeFilter(false)
+	filter["alumni_id"] = alumniObjID
+
+	// Urut sebagai timeline karier: pekerjaan pertama lebih dulu
+	opts := options.Find().SetSort(bson.D{{Key: "tanggal_mulai_kerja", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var result []model.PekerjaanAlumni
+	result := []model.PekerjaanAlumni{}
 	if err := cursor.All(ctx, &result); err != nil {
 		return nil, err
 	}
@@ -127,15 +197,41 @@ func (r *PekerjaanRepository) Create(ctx context.Context, pekerjaan *model.Peker
 	return pekerjaan, nil
 }
 
-// Update pekerjaan
-func (r *PekerjaanRepository) Update(ctx context.Context, id string, pekerjaan *model.PekerjaanAlumni) error {
+// Update pekerjaan aktif; false jika tidak ditemukan atau sudah di trash
+func (r *PekerjaanRepository) Update(ctx context.Context, id string, pekerjaan *model.PekerjaanAlumni) (bool, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("ID tidak valid")
+		return false, errors.New("ID tidak valid")
 	}
-	update := bson.M{"$set": pekerjaan}
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
-	return err
+
+	filter := activeFilter(false)
+	filter["_id"] = objID
+
+	// Hanya field yang bisa diedit; created_at dan is_deleted tidak boleh tertimpa
+	set := bson.M{
+		"alumni_id":             pekerjaan.AlumniID,
+		"nama_perusahaan":       pekerjaan.NamaPerusahaan,
+		"posisi_jabatan":        pekerjaan.PosisiJabatan,
+		"bidang_industri":       pekerjaan.BidangIndustri,
+		"lokasi_kerja":          pekerjaan.LokasiKerja,
+		"tanggal_mulai_kerja":   pekerjaan.TanggalMulaiKerja,
+		"tanggal_selesai_kerja": pekerjaan.TanggalSelesaiKerja,
+		"status_pekerjaan":      pekerjaan.StatusPekerjaan,
+		"deskripsi_pekerjaan":   pekerjaan.DeskripsiPekerjaan,
+		"updated_at":            pekerjaan.UpdatedAt,
+	}
+	update := bson.M{"$set": set}
+	if pekerjaan.Gaji != nil {
+		set["gaji"] = pekerjaan.Gaji
+	} else {
+		// Tanpa $unset gaji lama tetap tersimpan
+		update["$unset"] = bson.M{"gaji": ""}
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
 }
 
 // Hapus permanen
@@ -155,38 +251,135 @@ func (r *PekerjaanRepository) SoftDelete(ctx context.Context, id string, userID
 	if err != nil {
 		return errors.New("ID tidak valid")
 	}
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("User ID tidak valid")
+	}
 	update := bson.M{
 		"$set": bson.M{
 			"is_deleted": time.Now(), // <-- Diubah menjadi timestamp
-			"deleted_by": userID,
+			"deleted_by": userObjID,
 		},
 	}
 	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
 	return err
 }
 
-// Ambil daftar pekerjaan yang sudah dihapus
-// (FIXED: Mencari yang is_deleted ADA / NOT NIL)
-func (r *PekerjaanRepository) GetTrash(ctx context.Context, role string, userID *primitive.ObjectID) ([]model.TrashPekerjaan, error) {
+// Filter trash sesuai role dan TrashFilter
+func trashFilter(role string, userID *primitive.ObjectID, f model.TrashFilter) bson.M {
 	// Temukan dokumen di mana 'is_deleted' ada (bukan null)
-	filter := bson.M{"is_deleted": bson.M{"$ne": nil}}
+	deletedAt := bson.M{"$ne": nil}
+	if f.DeletedAfter != nil {
+		deletedAt["$gte"] = *f.DeletedAfter
+	}
+	if f.DeletedBefore != nil {
+		deletedAt["$lte"] = *f.DeletedBefore
+	}
 
+	filter := bson.M{"is_deleted": deletedAt}
 	if role != "admin" && userID != nil {
 		filter["alumni_id"] = *userID
 	}
+	if f.DeletedBy != nil {
+		// Data lama menyimpan deleted_by sebagai string hex
+		filter["deleted_by"] = bson.M{"$in": bson.A{*f.DeletedBy, f.DeletedBy.Hex()}}
+	}
+	return filter
+}
+
+// Ambil daftar pekerjaan yang sudah dihapus, lengkap dengan nama alumni
+// dan user yang menghapus. Mendukung pagination dan filter penghapus.
+func (r *PekerjaanRepository) GetTrash(ctx context.Context, role string, userID *primitive.ObjectID, f model.TrashFilter) ([]model.TrashPekerjaan, int, error) {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 {
+		f.Limit = 10
+	}
+	skip := (f.Page - 1) * f.Limit
+
+	filter := trashFilter(role, userID, f)
+
+	sortOrder := -1
+	if f.Order == "asc" || f.Order == "ASC" {
+		sortOrder = 1
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "is_deleted", Value: sortOrder}, {Key: "_id", Value: sortOrder}}}},
+		{{Key: "$skip", Value: skip}},
+		{{Key: "$limit", Value: f.Limit}},
+		{{Key: "$addFields", Value: bson.D{
+			{Key: "user_id", Value: bson.D{{Key: "$convert", Value: bson.D{
+				{Key: "input", Value: "$deleted_by"},
+				{Key: "to", Value: "objectId"},
+				{Key: "onError", Value: nil},
+				{Key: "onNull", Value: nil},
+			}}}},
+		}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: r.alumniColl.Name()},
+			{Key: "localField", Value: "alumni_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "alumni"},
+		}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: r.userColl.Name()},
+			{Key: "localField", Value: "user_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "penghapus"},
+		}}},
+		{{Key: "$addFields", Value: bson.D{
+			{Key: "nama_alumni", Value: bson.D{{Key: "$ifNull", Value: bson.A{
+				bson.D{{Key: "$arrayElemAt", Value: bson.A{"$alumni.nama", 0}}}, "",
+			}}}},
+			{Key: "deleted_by_username", Value: bson.D{{Key: "$ifNull", Value: bson.A{
+				bson.D{{Key: "$arrayElemAt", Value: bson.A{"$penghapus.username", 0}}}, "",
+			}}}},
+		}}},
+		{{Key: "$project", Value: bson.D{{Key: "alumni", Value: 0}, {Key: "penghapus", Value: 0}}}},
+	}
 
-	cursor, err := r.collection.Find(ctx, filter)
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
-	// Model TrashPekerjaan Anda sudah benar menggunakan *time.Time
-	var trashList []model.TrashPekerjaan
+	trashList := []model.TrashPekerjaan{}
 	if err := cursor.All(ctx, &trashList); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return trashList, int(total), nil
+}
+
+// Ambil ID semua pekerjaan di trash yang cocok dengan filter (untuk operasi massal)
+func (r *PekerjaanRepository) GetTrashIDs(ctx context.Context, role string, userID *primitive.ObjectID, f model.TrashFilter) ([]primitive.ObjectID, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, trashFilter(role, userID, f), opts)
+	if err != nil {
 		return nil, err
 	}
-	return trashList, nil
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return ids, nil
 }
 
 // Restore pekerjaan
@@ -260,4 +453,143 @@ func (r *PekerjaanRepository) GetOwnerAndDeleteStatus(ctx context.Context, id st
 	}
 
 	return &ownerID, &isDeleted, nil
-}
\ No newline at end of file
+}
+
+// Ambil pekerjaan di trash yang dihapus sebelum cutoff (kandidat purge)
+func (r *PekerjaanRepository) GetExpiredTrash(ctx context.Context, cutoff time.Time) ([]model.TrashPekerjaan, error) {
+	filter := bson.M{"is_deleted": bson.M{"$ne": nil, "$lte": cutoff}}
+	opts := options.Find().SetSort(bson.D{{Key: "is_deleted", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	trashList := []model.TrashPekerjaan{}
+	if err := cursor.All(ctx, &trashList); err != nil {
+		return nil, err
+	}
+	return trashList, nil
+}
+
+// Hapus permanen pekerjaan di trash yang sudah melewati cutoff.
+// Filter is_deleted diulang agar data yang baru saja direstore tidak ikut terhapus.
+func (r *PekerjaanRepository) PurgeTrash(ctx context.Context, ids []primitive.ObjectID, cutoff time.Time) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	filter := bson.M{
+		"_id":        bson.M{"$in": ids},
+		"is_deleted": bson.M{"$ne": nil, "$lte": cutoff},
+	}
+	res, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// Ambil dokumen yang tanggal_mulai_kerja / tanggal_selesai_kerja masih berupa string
+func (r *PekerjaanRepository) GetLegacyTanggal(ctx context.Context) ([]model.LegacyTanggalPekerjaan, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"tanggal_mulai_kerja": bson.M{"$type": "string"}},
+		bson.M{"tanggal_selesai_kerja": bson.M{"$type": "string"}},
+	}}
+	opts := options.Find().SetProjection(bson.M{"tanggal_mulai_kerja": 1, "tanggal_selesai_kerja": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []model.LegacyTanggalPekerjaan
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Simpan tanggal hasil migrasi sebagai tipe date
+func (r *PekerjaanRepository) SetTanggal(ctx context.Context, id primitive.ObjectID, mulai time.Time, selesai *time.Time) error {
+	update := bson.M{"$set": bson.M{
+		"tanggal_mulai_kerja":   mulai,
+		"tanggal_selesai_kerja": selesai,
+	}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Ambil dokumen yang masih memakai gaji_range string dan belum punya gaji terstruktur
+func (r *PekerjaanRepository) GetLegacyGaji(ctx context.Context) ([]model.LegacyGajiPekerjaan, error) {
+	filter := bson.M{
+		"gaji_range": bson.M{"$type": "string"},
+		"gaji":       bson.M{"$exists": false},
+	}
+	opts := options.Find().SetProjection(bson.M{"gaji_range": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []model.LegacyGajiPekerjaan
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Simpan gaji terstruktur hasil migrasi dan hapus gaji_range lama.
+// gaji nil berarti gaji_range kosong: field lama tetap dihapus.
+func (r *PekerjaanRepository) SetGaji(ctx context.Context, id primitive.ObjectID, gaji *model.Gaji) error {
+	update := bson.M{"$unset": bson.M{"gaji_range": ""}}
+	if gaji != nil {
+		update["$set"] = bson.M{"gaji": gaji}
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Ambil status_pekerjaan dan bidang_industri semua pekerjaan (termasuk di trash)
+func (r *PekerjaanRepository) GetTaksonomiValues(ctx context.Context) ([]model.LegacyTaksonomiPekerjaan, error) {
+	opts := options.Find().SetProjection(bson.M{"status_pekerjaan": 1, "bidang_industri": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []model.LegacyTaksonomiPekerjaan
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Simpan kode baku status_pekerjaan dan bidang_industri
+func (r *PekerjaanRepository) SetTaksonomi(ctx context.Context, id primitive.ObjectID, status, bidang string) error {
+	update := bson.M{"$set": bson.M{
+		"status_pekerjaan": status,
+		"bidang_industri":  bidang,
+	}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Hitung pekerjaan (termasuk yang di trash) yang memakai kode taksonomi.
+// jenis sama dengan nama field-nya: status_pekerjaan atau bidang_industri.
+func (r *PekerjaanRepository) CountByTaksonomi(ctx context.Context, jenis, kode string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{jenis: kode})
+}
+
+// Ganti kode taksonomi from menjadi to di semua pekerjaan, termasuk yang di trash
+func (r *PekerjaanRepository) ReplaceTaksonomi(ctx context.Context, jenis, from, to string) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx, bson.M{jenis: from}, bson.M{"$set": bson.M{jenis: to}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
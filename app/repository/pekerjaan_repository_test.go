@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"praktikummongo/app/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Update hanya mengubah field yang bisa diedit; created_at tetap
+func TestPekerjaanUpdatePertahankanCreatedAt(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewPekerjaanRepository(db)
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	p, err := repo.Create(ctx, &model.PekerjaanAlumni{
+		AlumniID:          primitive.NewObjectID(),
+		NamaPerusahaan:    "PT Lama",
+		TanggalMulaiKerja: created,
+		Gaji:              &model.Gaji{Min: 5000000, Max: 7000000, Currency: "IDR", Period: "bulan"},
+		CreatedAt:         created,
+		UpdatedAt:         created,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Seperti hasil parsePekerjaanInput: tanpa created_at dan tanpa gaji
+	found, err := repo.Update(ctx, p.ID.Hex(), &model.PekerjaanAlumni{
+		AlumniID:          p.AlumniID,
+		NamaPerusahaan:    "PT Baru",
+		TanggalMulaiKerja: created,
+		UpdatedAt:         time.Now(),
+	})
+	if err != nil || !found {
+		t.Fatalf("Update = %v, %v", found, err)
+	}
+
+	got, err := repo.GetByID(ctx, p.ID.Hex(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.NamaPerusahaan != "PT Baru" {
+		t.Errorf("nama_perusahaan = %q, want PT Baru", got.NamaPerusahaan)
+	}
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("created_at = %v, want %v", got.CreatedAt, created)
+	}
+	if got.Gaji != nil {
+		t.Errorf("gaji = %+v, want dihapus", got.Gaji)
+	}
+}
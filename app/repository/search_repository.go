@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"praktikummongo/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ISearchRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	SearchAlumni(ctx context.Context, q string, limit int) ([]model.AlumniSearchResult, int64, error)
+	SearchPekerjaan(ctx context.Context, q string, limit int) ([]model.PekerjaanSearchResult, int64, error)
+}
+
+type SearchRepository struct {
+	alumniColl    *mongo.Collection
+	pekerjaanColl *mongo.Collection
+}
+
+func NewSearchRepository(db *mongo.Database) ISearchRepository {
+	return &SearchRepository{
+		alumniColl:    db.Collection("alumni"),
+		pekerjaanColl: db.Collection("pekerjaan_alumni"),
+	}
+}
+
+// EnsureIndexes membuat text index untuk pencarian. Bahasa "none" karena MongoDB
+// tidak punya stemmer bahasa Indonesia; bobot menentukan urutan relevansi.
+func (r *SearchRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.alumniColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "nama", Value: "text"}, {Key: "nim", Value: "text"}, {Key: "jurusan", Value: "text"}},
+		Options: options.Index().
+			SetName("alumni_text").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{{Key: "nama", Value: 10}, {Key: "nim", Value: 10}, {Key: "jurusan", Value: 3}}),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pekerjaanColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "nama_perusahaan", Value: "text"}, {Key: "posisi_jabatan", Value: "text"}, {Key: "bidang_industri", Value: "text"}},
+		Options: options.Index().
+			SetName("pekerjaan_text").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{{Key: "nama_perusahaan", Value: 5}, {Key: "posisi_jabatan", Value: 5}, {Key: "bidang_industri", Value: 2}}),
+	})
+	return err
+}
+
+// Cari alumni dengan text index, urut berdasarkan skor relevansi.
+// Mengembalikan hasil teratas sebanyak limit dan jumlah total yang cocok.
+func (r *SearchRepository) SearchAlumni(ctx context.Context, q string, limit int) ([]model.AlumniSearchResult, int64, error) {
+	filter := bson.M{"$text": bson.M{"$search": q}}
+	total, err := r.alumniColl.CountDocuments(ctx, filter)
+	if err != nil || total == 0 || limit <= 0 {
+		return []model.AlumniSearchResult{}, total, err
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.alumniColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []model.AlumniSearchResult{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+
+// Cari pekerjaan aktif (bukan trash) dengan text index, disertai nama alumninya
+func (r *SearchRepository) SearchPekerjaan(ctx context.Context, q string, limit int) ([]model.PekerjaanSearchResult, int64, error) {
+	filter := activeFilter(false)
+	filter["$text"] = bson.M{"$search": q}
+	total, err := r.pekerjaanColl.CountDocuments(ctx, filter)
+	if err != nil || total == 0 || limit <= 0 {
+		return []model.PekerjaanSearchResult{}, total, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "alumni",
+			"localField":   "alumni_id",
+			"foreignField": "_id",
+			"as":           "alumni",
+		}}},
+		{{Key: "$addFields", Value: bson.M{"alumni_nama": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$alumni.nama", 0}}, ""}}}}},
+		{{Key: "$project", Value: bson.M{"alumni": 0}}},
+	}
+	cursor, err := r.pekerjaanColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []model.PekerjaanSearchResult{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"praktikummongo/app/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IStatsCacheRepository interface {
+	Get(ctx context.Context, key string) (*model.StatsCache, error)
+	Save(ctx context.Context, entry *model.StatsCache) error
+	GetAll(ctx context.Context, name string) ([]model.StatsCache, error)
+	Touch(ctx context.Context, key string, readAt time.Time) error
+	Expire(ctx context.Context) error
+	Delete(ctx context.Context, key string) error
+	EnsureIndexes(ctx context.Context, ttl time.Duration) error
+}
+
+type StatsCacheRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStatsCacheRepository(db *mongo.Database) IStatsCacheRepository {
+	return &StatsCacheRepository{collection: db.Collection("stats_cache")}
+}
+
+// Ambil cache berdasarkan key, (nil, nil) jika belum ada
+func (r *StatsCacheRepository) Get(ctx context.Context, key string) (*model.StatsCache, error) {
+	var entry model.StatsCache
+	err := r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Simpan (upsert) hasil statistik. read_at tidak pernah dimundurkan agar refresh
+// background tidak menimpa waktu baca dari request yang terjadi bersamaan.
+func (r *StatsCacheRepository) Save(ctx context.Context, entry *model.StatsCache) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":         entry.Name,
+			"params":       entry.Params,
+			"data":         entry.Data,
+			"generated_at": entry.GeneratedAt,
+		},
+		"$max": bson.M{"read_at": entry.ReadAt},
+	}
+	opts := options.Update().SetUpsert(true)
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": entry.Key}, update, opts)
+	return err
+}
+
+// Catat waktu baca terakhir entri
+func (r *StatsCacheRepository) Touch(ctx context.Context, key string, readAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$max": bson.M{"read_at": readAt}})
+	return err
+}
+
+// Tandai semua entri kedaluwarsa tanpa menghapusnya, agar tetap ikut refresh berkala
+func (r *StatsCacheRepository) Expire(ctx context.Context) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{}, bson.M{"$set": bson.M{"generated_at": time.Time{}}})
+	return err
+}
+
+// Hapus satu entri
+func (r *StatsCacheRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
+
+// EnsureIndexes membuat TTL index: entri yang tidak dibaca selama ttl dihapus MongoDB
+func (r *StatsCacheRepository) EnsureIndexes(ctx context.Context, ttl time.Duration) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "read_at", Value: 1}},
+		Options: options.Index().SetName("read_at_ttl").SetExpireAfterSeconds(int32(ttl.Seconds())),
+	})
+	return err
+}
+
+// Ambil semua cache, atau hanya untuk satu nama statistik jika name diisi
+func (r *StatsCacheRepository) GetAll(ctx context.Context, name string) ([]model.StatsCache, error) {
+	filter := bson.M{}
+	if name != "" {
+		filter["name"] = name
+	}
+	opts := options.Find().SetProjection(bson.M{"data": 0})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []model.StatsCache{}
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
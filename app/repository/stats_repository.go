@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"praktikummongo/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IStatsRepository interface {
+	GetGajiByKelompok(ctx context.Context, groupBy, currency string, f model.AlumniFilter) ([]model.GajiKelompok, error)
+	GetTingkatKerja(ctx context.Context, groupBy string, f model.AlumniFilter) ([]model.TingkatKerja, error)
+	GetWaktuTunggu(ctx context.Context, groupBy string, f model.AlumniFilter) ([]model.WaktuTungguKelompok, error)
+	GetDistribusi(ctx context.Context, field string, f model.AlumniFilter, limit int, saatIni bool) ([]model.Distribusi, error)
+}
+
+type StatsRepository struct {
+	alumniColl    *mongo.Collection
+	pekerjaanColl *mongo.Collection
+}
+
+func NewStatsRepository(db *mongo.Database) IStatsRepository {
+	return &StatsRepository{
+		alumniColl:    db.Collection("alumni"),
+		pekerjaanColl: db.Collection("pekerjaan_alumni"),
+	}
+}
+
+// ErrGroupByTidakDidukung dikembalikan jika parameter group_by tidak dikenal
+var ErrGroupByTidakDidukung = errors.New("group_by tidak didukung")
+
+// Field pengelompokan yang didukung statistik pekerjaan.
+// Field alumni (jurusan, angkatan) diambil lewat $lookup.
+var kelompokPekerjaan = map[string]string{
+	"jurusan":         "$alumni.jurusan",
+	"angkatan":        "$alumni.angkatan",
+	"bidang_industri": "$bidang_industri",
+}
+
+// Field pengelompokan untuk statistik yang berangkat dari koleksi alumni.
+// String kosong berarti satu kelompok untuk semua alumni.
+var kelompokAlumni = map[string]interface{}{
+	"":            nil,
+	"jurusan":     "$jurusan",
+	"angkatan":    "$angkatan",
+	"tahun_lulus": "$tahun_lulus",
+}
+
+// Field pekerjaan yang bisa dihitung distribusinya
+var distribusiPekerjaan = map[string]bool{
+	"nama_perusahaan": true,
+	"bidang_industri": true,
+	"lokasi_kerja":    true,
+}
+
+// TahunLulus hanya menyimpan tahun, jadi bulan kelulusan diasumsikan pertengahan tahun
+const bulanLulusAsumsi = 7
+
+// lookupAlumniStages menggabungkan data alumni ke setiap pekerjaan lalu
+// menerapkan filter alumni
+func (r *StatsRepository) lookupAlumniStages(f model.AlumniFilter) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: r.alumniColl.Name()},
+			{Key: "localField", Value: "alumni_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "alumni"},
+		}}},
+		{{Key: "$unwind", Value: "$alumni"}},
+		{{Key: "$match", Value: alumniMatch(f, "alumni.")}},
+	}
+}
+
+// lookupPekerjaanStage menggabungkan pekerjaan aktif (bukan di trash) ke setiap alumni,
+// urut dari pekerjaan pertama
+func (r *StatsRepository) lookupPekerjaanStage() bson.D {
+	return bson.D{{Key: "$lookup", Value: bson.D{
+		{Key: "from", Value: r.pekerjaanColl.Name()},
+		{Key: "let", Value: bson.D{{Key: "aid", Value: "$_id"}}},
+		{Key: "pipeline", Value: mongo.Pipeline{
+			{{Key: "$match", Value: bson.D{
+				{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$alumni_id", "$$aid"}}}},
+				{Key: "is_deleted", Value: nil},
+			}}},
+			{{Key: "$sort", Value: bson.D{{Key: "tanggal_mulai_kerja", Value: 1}}}},
+			{{Key: "$project", Value: bson.D{
+				{Key: "tanggal_mulai_kerja", Value: 1},
+				{Key: "tanggal_selesai_kerja", Value: 1},
+			}}},
+		}},
+		{Key: "as", Value: "pekerjaan"},
+	}}}
+}
+
+// GetGajiByKelompok - Mengumpulkan gaji bulanan (titik tengah rentang, gaji tahunan
+// dibagi 12) per kelompok. Rentang terbuka memakai batas yang diketahui: "< 5 juta"
+// dihitung 5 juta, "> 10 juta" dihitung 10 juta. Persentil dihitung di service.
+func (r *StatsRepository) GetGajiByKelompok(ctx context.Context, groupBy, currency string, f model.AlumniFilter) ([]model.GajiKelompok, error) {
+	key, ok := kelompokPekerjaan[groupBy]
+	if !ok {
+		return nil, ErrGroupByTidakDidukung
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"is_deleted":    nil,
+			"gaji":          bson.M{"$exists": true},
+			"gaji.currency": currency,
+		}}},
+	}
+	pipeline = append(pipeline, r.lookupAlumniStages(f)...)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "nilai", Value: bson.D{{Key: "$switch", Value: bson.D{
+				{Key: "branches", Value: bson.A{
+					bson.D{
+						{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$gaji.max", 0}}}},
+						{Key: "then", Value: "$gaji.min"},
+					},
+					bson.D{
+						{Key: "case", Value: bson.D{{Key: "$lte", Value: bson.A{"$gaji.min", 0}}}},
+						{Key: "then", Value: "$gaji.max"},
+					},
+				}},
+				{Key: "default", Value: bson.D{{Key: "$avg", Value: bson.A{"$gaji.min", "$gaji.max"}}}},
+			}}}},
+		}}},
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "nilai", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$eq", Value: bson.A{"$gaji.period", "tahun"}}},
+				bson.D{{Key: "$divide", Value: bson.A{"$nilai", 12}}},
+				"$nilai",
+			}}}},
+		}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: key},
+			{Key: "nilai", Value: bson.D{{Key: "$push", Value: "$nilai"}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	)
+
+	cursor, err := r.pekerjaanColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []model.GajiKelompok
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetTingkatKerja - Jumlah alumni, yang pernah bekerja, dan yang sedang bekerja per kelompok
+func (r *StatsRepository) GetTingkatKerja(ctx context.Context, groupBy string, f model.AlumniFilter) ([]model.TingkatKerja, error) {
+	key, ok := kelompokAlumni[groupBy]
+	if !ok {
+		return nil, ErrGroupByTidakDidukung
+	}
+
+	// Pekerjaan berjalan: tanggal_selesai_kerja null atau tidak ada
+	berjalan := bson.D{{Key: "$filter", Value: bson.D{
+		{Key: "input", Value: "$pekerjaan"},
+		{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$$this.tanggal_selesai_kerja", nil}}}, nil,
+		}}}},
+	}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: alumniMatch(f, "")}},
+		r.lookupPekerjaanStage(),
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: key},
+			{Key: "total_alumni", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "pernah_bekerja", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{bson.D{{Key: "$size", Value: "$pekerjaan"}}, 0}}}, 1, 0,
+			}}}}}},
+			{Key: "bekerja_saat_ini", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{bson.D{{Key: "$size", Value: berjalan}}, 0}}}, 1, 0,
+			}}}}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.alumniColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []model.TingkatKerja{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetWaktuTunggu - Selisih bulan antara kelulusan dan pekerjaan pertama per alumni,
+// dikumpulkan per kelompok. Pekerjaan yang dimulai sebelum lulus dihitung 0 bulan.
+func (r *StatsRepository) GetWaktuTunggu(ctx context.Context, groupBy string, f model.AlumniFilter) ([]model.WaktuTungguKelompok, error) {
+	key, ok := kelompokAlumni[groupBy]
+	if !ok {
+		return nil, ErrGroupByTidakDidukung
+	}
+
+	match := alumniMatch(f, "")
+	if _, set := match["tahun_lulus"]; !set {
+		match["tahun_lulus"] = bson.M{"$gt": 0}
+	}
+
+	// Bulan ke-n sejak tahun 0, untuk pekerjaan pertama dan untuk kelulusan
+	mulai := "$pertama.tanggal_mulai_kerja"
+	bulanMulai := bson.D{{Key: "$add", Value: bson.A{
+		bson.D{{Key: "$multiply", Value: bson.A{bson.D{{Key: "$year", Value: mulai}}, 12}}},
+		bson.D{{Key: "$month", Value: mulai}},
+	}}}
+	bulanLulus := bson.D{{Key: "$add", Value: bson.A{
+		bson.D{{Key: "$multiply", Value: bson.A{"$tahun_lulus", 12}}},
+		bulanLulusAsumsi,
+	}}}
+	selisih := bson.D{{Key: "$subtract", Value: bson.A{bulanMulai, bulanLulus}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		r.lookupPekerjaanStage(),
+		{{Key: "$addFields", Value: bson.D{{Key: "pertama", Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$pekerjaan", 0}}}}}}},
+		{{Key: "$match", Value: bson.D{{Key: "pertama.tanggal_mulai_kerja", Value: bson.D{{Key: "$type", Value: "date"}}}}}},
+		{{Key: "$addFields", Value: bson.D{{Key: "bulan", Value: bson.D{{Key: "$max", Value: bson.A{0, selisih}}}}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: key},
+			{Key: "bulan", Value: bson.D{{Key: "$push", Value: "$bulan"}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.alumniColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []model.WaktuTungguKelompok
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetDistribusi - Jumlah pekerjaan dan alumni per nilai field (perusahaan, industri,
+// lokasi), urut dari alumni terbanyak. Nama perusahaan/lokasi dikelompokkan tanpa
+// membedakan huruf besar-kecil. saatIni membatasi ke pekerjaan yang masih berjalan.
+func (r *StatsRepository) GetDistribusi(ctx context.Context, field string, f model.AlumniFilter, limit int, saatIni bool) ([]model.Distribusi, error) {
+	if !distribusiPekerjaan[field] {
+		return nil, ErrGroupByTidakDidukung
+	}
+
+	match := bson.M{
+		"is_deleted": nil,
+		field:        bson.M{"$nin": bson.A{nil, ""}},
+	}
+	if saatIni {
+		match["tanggal_selesai_kerja"] = nil
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+	pipeline = append(pipeline, r.lookupAlumniStages(f)...)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$toLower", Value: bson.D{{Key: "$trim", Value: bson.D{{Key: "input", Value: "$" + field}}}}}}},
+			{Key: "nama", Value: bson.D{{Key: "$first", Value: "$" + field}}},
+			{Key: "jumlah_pekerjaan", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "alumni", Value: bson.D{{Key: "$addToSet", Value: "$alumni_id"}}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "nama", Value: 1},
+			{Key: "jumlah_pekerjaan", Value: 1},
+			{Key: "jumlah_alumni", Value: bson.D{{Key: "$size", Value: "$alumni"}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "jumlah_alumni", Value: -1}, {Key: "jumlah_pekerjaan", Value: -1}, {Key: "nama", Value: 1}}}},
+	)
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	cursor, err := r.pekerjaanColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []model.Distribusi{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"praktikummongo/app/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ITaksonomiRepository interface {
+	GetAll(ctx context.Context, jenis string) ([]model.Taksonomi, error)
+	GetByID(ctx context.Context, id string) (*model.Taksonomi, error)
+	Resolve(ctx context.Context, jenis, value string) (*model.Taksonomi, error)
+	Create(ctx context.Context, t *model.Taksonomi) (*model.Taksonomi, error)
+	Update(ctx context.Context, id string, t *model.Taksonomi) error
+	Delete(ctx context.Context, id string) error
+	Count(ctx context.Context, jenis string) (int64, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type TaksonomiRepository struct {
+	collection *mongo.Collection
+}
+
+func NewTaksonomiRepository(db *mongo.Database) ITaksonomiRepository {
+	return &TaksonomiRepository{collection: db.Collection("taksonomi")}
+}
+
+// Ambil semua istilah untuk satu jenis, urut berdasarkan nama
+func (r *TaksonomiRepository) GetAll(ctx context.Context, jenis string) ([]model.Taksonomi, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "nama", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"jenis": jenis}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []model.Taksonomi{}
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Ambil istilah berdasarkan ID
+func (r *TaksonomiRepository) GetByID(ctx context.Context, id string) (*model.Taksonomi, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("ID tidak valid")
+	}
+
+	var t model.Taksonomi
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&t)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Cari istilah yang kode atau aliasnya sama dengan value (value sudah dinormalisasi).
+// Mengembalikan (nil, nil) jika tidak ada yang cocok.
+func (r *TaksonomiRepository) Resolve(ctx context.Context, jenis, value string) (*model.Taksonomi, error) {
+	filter := bson.M{
+		"jenis": jenis,
+		"$or":   bson.A{bson.M{"kode": value}, bson.M{"alias": value}},
+	}
+
+	var t model.Taksonomi
+	err := r.collection.FindOne(ctx, filter).Decode(&t)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Tambah istilah baru
+func (r *TaksonomiRepository) Create(ctx context.Context, t *model.Taksonomi) (*model.Taksonomi, error) {
+	t.ID = primitive.NilObjectID
+	res, err := r.collection.InsertOne(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	t.ID = res.InsertedID.(primitive.ObjectID)
+	return t, nil
+}
+
+// Update nama dan alias istilah. Kode tidak bisa diubah karena sudah dipakai di data pekerjaan.
+func (r *TaksonomiRepository) Update(ctx context.Context, id string, t *model.Taksonomi) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("ID tidak valid")
+	}
+	update := bson.M{"$set": bson.M{
+		"nama":       t.Nama,
+		"alias":      t.Alias,
+		"updated_at": t.UpdatedAt,
+	}}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	return err
+}
+
+// Hapus istilah
+func (r *TaksonomiRepository) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("ID tidak valid")
+	}
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+// Hitung jumlah istilah untuk satu jenis
+func (r *TaksonomiRepository) Count(ctx context.Context, jenis string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"jenis": jenis})
+}
+
+// EnsureIndexes membuat unique index (jenis, kode) agar dua istilah yang dibuat
+// bersamaan tidak bisa memakai kode yang sama
+func (r *TaksonomiRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "jenis", Value: 1}, {Key: "kode", Value: 1}},
+		Options: options.Index().SetName("jenis_kode_unique").SetUnique(true),
+	})
+	return err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"praktikummongo/app/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IUploadSessionRepository interface {
+	Create(ctx context.Context, u *model.UploadSession) (*model.UploadSession, error)
+	GetByID(ctx context.Context, id string) (*model.UploadSession, error)
+	MarkChunk(ctx context.Context, id primitive.ObjectID, index int, expiresAt time.Time) error
+	SetStatus(ctx context.Context, id primitive.ObjectID, from, to string) (bool, error)
+	ClaimAssembling(ctx context.Context, id primitive.ObjectID, staleBefore time.Time) (bool, error)
+	ResetChunks(ctx context.Context, id primitive.ObjectID) error
+	Complete(ctx context.Context, id, fileID primitive.ObjectID) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	GetExpired(ctx context.Context, now time.Time) ([]model.UploadSession, error)
+}
+
+type UploadSessionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewUploadSessionRepository(db *mongo.Database) IUploadSessionRepository {
+	return &UploadSessionRepository{collection: db.Collection("upload_sessions")}
+}
+
+// Simpan sesi upload baru
+func (r *UploadSessionRepository) Create(ctx context.Context, u *model.UploadSession) (*model.UploadSession, error) {
+	u.ID = primitive.NilObjectID
+	res, err := r.collection.InsertOne(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	u.ID = res.InsertedID.(primitive.ObjectID)
+	return u, nil
+}
+
+// Ambil sesi berdasarkan ID
+func (r *UploadSessionRepository) GetByID(ctx context.Context, id string) (*model.UploadSession, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("ID tidak valid")
+	}
+
+	var u model.UploadSession
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&u)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Catat potongan yang sudah diterima dan perpanjang masa berlaku sesi
+func (r *UploadSessionRepository) MarkChunk(ctx context.Context, id primitive.ObjectID, index int, expiresAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.UploadActive},
+		bson.M{
+			"$addToSet": bson.M{"received": index},
+			"$set":      bson.M{"expires_at": expiresAt},
+		})
+	return err
+}
+
+// Ubah status hanya jika status saat ini sama dengan from.
+// Dipakai agar complete tidak berjalan dua kali untuk sesi yang sama.
+func (r *UploadSessionRepository) SetStatus(ctx context.Context, id primitive.ObjectID, from, to string) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": bson.M{"status": to}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// Tandai sesi sedang digabung. Berhasil jika sesi aktif, atau masih assembling tetapi
+// klaimnya lebih lama dari staleBefore (proses complete sebelumnya mati / timeout).
+func (r *UploadSessionRepository) ClaimAssembling(ctx context.Context, id primitive.ObjectID, staleBefore time.Time) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "$or": []bson.M{
+			{"status": model.UploadActive},
+			{"status": model.UploadAssembling, "assembling_at": bson.M{"$lt": staleBefore}},
+		}},
+		bson.M{"$set": bson.M{"status": model.UploadAssembling, "assembling_at": time.Now()}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// Kosongkan daftar potongan (misalnya setelah checksum gagal) dan aktifkan lagi sesi
+func (r *UploadSessionRepository) ResetChunks(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"received": []int{}, "status": model.UploadActive}})
+	return err
+}
+
+// Tandai sesi selesai beserta ID file hasilnya
+func (r *UploadSessionRepository) Complete(ctx context.Context, id, fileID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"status": model.UploadCompleted, "file_id": fileID}})
+	return err
+}
+
+// Hapus sesi
+func (r *UploadSessionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// Ambil sesi yang sudah kedaluwarsa per waktu now
+func (r *UploadSessionRepository) GetExpired(ctx context.Context, now time.Time) ([]model.UploadSession, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"expires_at": bson.M{"$lt": now}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []model.UploadSession{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
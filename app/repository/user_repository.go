@@ -13,6 +13,8 @@ import (
 type IUserRepository interface {
 	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
 	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
+	GetUserByID(ctx context.Context, id primitive.ObjectID) (*model.User, error)
+	SetStorageQuota(ctx context.Context, id primitive.ObjectID, quota *int64) (bool, error)
 }
 
 type UserRepository struct {
@@ -62,4 +64,30 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *model.User) (*mod
 	// --- END PERBAIKAN ---
 
 	return user, nil
-}
\ No newline at end of file
+}
+
+// Ambil user berdasarkan ID
+func (r *UserRepository) GetUserByID(ctx context.Context, id primitive.ObjectID) (*model.User, error) {
+	var user model.User
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Atur kuota penyimpanan khusus user; nil menghapusnya sehingga kembali ke kuota role
+func (r *UserRepository) SetStorageQuota(ctx context.Context, id primitive.ObjectID, quota *int64) (bool, error) {
+	update := bson.M{"$unset": bson.M{"storage_quota": ""}}
+	if quota != nil {
+		update = bson.M{"$set": bson.M{"storage_quota": *quota}}
+	}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
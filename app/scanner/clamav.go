@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ClamAV memindai lewat socket clamd dengan perintah INSTREAM.
+// Alamat berupa tcp://host:port atau unix:///path/clamd.sock.
+type ClamAV struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func NewClamAV(address string, timeout time.Duration) (*ClamAV, error) {
+	network, addr, ok := strings.Cut(address, "://")
+	if !ok || (network != "tcp" && network != "unix") || addr == "" {
+		return nil, fmt.Errorf("CLAMAV_ADDRESS '%s' harus berformat tcp://host:port atau unix:///path", address)
+	}
+	return &ClamAV{network: network, address: addr, timeout: timeout}, nil
+}
+
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("gagal terhubung ke clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	// Format INSTREAM: tiap potongan diawali panjang 4 byte big-endian, diakhiri panjang 0
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+	chunk := make([]byte, 32*1024)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, werr := conn.Write(size); werr != nil {
+				return nil, werr
+			}
+			if _, werr := conn.Write(chunk[:n]); werr != nil {
+				// clamd menutup koneksi jika StreamMaxLength terlampaui; balasannya tetap dibaca
+				break
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	conn.Write(size)
+
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("gagal membaca balasan clamd: %w", err)
+	}
+	return parseClamReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamReply membaca balasan seperti "stream: OK" atau "stream: Eicar-Signature FOUND"
+func parseClamReply(reply string) (*Result, error) {
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("clamd: %s", reply)
+	}
+}
@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Result adalah hasil pemindaian satu file
+type Result struct {
+	Infected  bool
+	Signature string // nama malware yang terdeteksi, kosong jika bersih
+}
+
+// Scanner memeriksa isi file sebelum boleh diunduh
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// NewFromEnv memilih scanner dari SCANNER_BACKEND: noop (default) atau clamav
+func NewFromEnv() (Scanner, error) {
+	switch backend := os.Getenv("SCANNER_BACKEND"); backend {
+	case "", "noop":
+		return NewNoop(), nil
+	case "clamav":
+		address := os.Getenv("CLAMAV_ADDRESS")
+		if address == "" {
+			address = "tcp://localhost:3310"
+		}
+		return NewClamAV(address, 2*time.Minute)
+	default:
+		return nil, fmt.Errorf("SCANNER_BACKEND '%s' tidak dikenal (noop, clamav)", backend)
+	}
+}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// File dengan baris lebih banyak dari ini diproses sebagai job background
+	importSyncLimit = 500
+	// Batas baris laporan per-baris yang disimpan di satu dokumen job
+	importMaxRows = 20000
+)
+
+// Kolom alumni yang bisa diisi lewat import, beserta nama header yang dikenali otomatis
+var importKolom = map[string][]string{
+	"nim":         {"nim", "nomor induk mahasiswa"},
+	"nama":        {"nama", "nama lengkap", "nama mahasiswa"},
+	"jurusan":     {"jurusan", "prodi", "program studi"},
+	"angkatan":    {"angkatan", "tahun masuk"},
+	"tahun_lulus": {"tahun lulus", "tahun_lulus", "lulus"},
+	"email":       {"email", "e-mail", "surel"},
+	"no_telepon":  {"no telepon", "no_telepon", "no hp", "telepon", "hp"},
+	"alamat":      {"alamat"},
+}
+
+// AlumniImportService mengimpor daftar alumni dari file CSV / XLSX
+type AlumniImportService struct {
+	repo    repository.IAlumniRepository
+	jobRepo repository.IImportJobRepository
+	cache   *StatsCacheService
+}
+
+func NewAlumniImportService(repo repository.IAlumniRepository, jobRepo repository.IImportJobRepository, cache *StatsCacheService) *AlumniImportService {
+	return &AlumniImportService{repo: repo, jobRepo: jobRepo, cache: cache}
+}
+
+// validateAlumni dipakai oleh Create dan import
+func validateAlumni(a *model.Alumni) error {
+	a.NIM = strings.TrimSpace(a.NIM)
+	a.Nama = strings.TrimSpace(a.Nama)
+	a.Email = strings.TrimSpace(a.Email)
+	if a.NIM == "" {
+		return errors.New("NIM wajib diisi")
+	}
+	if a.Nama == "" {
+		return errors.New("nama wajib diisi")
+	}
+	if a.Angkatan != 0 && (a.Angkatan < 1950 || a.Angkatan > time.Now().Year()) {
+		return fmt.Errorf("angkatan %d tidak valid", a.Angkatan)
+	}
+	if a.TahunLulus != 0 {
+		if a.TahunLulus < 1950 || a.TahunLulus > time.Now().Year()+1 {
+			return fmt.Errorf("tahun lulus %d tidak valid", a.TahunLulus)
+		}
+		if a.Angkatan != 0 && a.TahunLulus < a.Angkatan {
+			return errors.New("tahun lulus tidak boleh sebelum angkatan")
+		}
+	}
+	if a.Email != "" {
+		if _, err := mail.ParseAddress(a.Email); err != nil {
+			return fmt.Errorf("email '%s' tidak valid", a.Email)
+		}
+	}
+	return nil
+}
+
+// ------------------- Baca File -------------------
+
+// readImportFile membaca file upload menjadi baris-baris sel; baris pertama adalah header
+func readImportFile(c *fiber.Ctx) (string, [][]string, error) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return "", nil, errors.New("file wajib diupload pada field 'file'")
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(fh.Filename)) {
+	case ".csv":
+		rows, err = readCSV(data)
+	case ".xlsx":
+		rows, err = readXLSX(data)
+	default:
+		return "", nil, errors.New("format file harus .csv atau .xlsx")
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("gagal membaca file: %w", err)
+	}
+	if len(rows) < 1 {
+		return "", nil, errors.New("file kosong")
+	}
+	return fh.Filename, rows, nil
+}
+
+// readCSV mendeteksi pemisah ';' (Excel lokal Indonesia) atau ','
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	return r.ReadAll()
+}
+
+// readXLSX membaca sheet pertama
+func readXLSX(data []byte) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("workbook tidak memiliki sheet")
+	}
+	return f.GetRows(sheets[0])
+}
+
+// suggestMapping mencocokkan header file ke kolom alumni
+func suggestMapping(header []string) map[string]int {
+	mapping := map[string]int{}
+	for i, h := range header {
+		h = normalizeIstilah(strings.ReplaceAll(h, "_", " "))
+		for kolom, names := range importKolom {
+			if _, ok := mapping[kolom]; ok {
+				continue
+			}
+			for _, n := range names {
+				if h == normalizeIstilah(strings.ReplaceAll(n, "_", " ")) {
+					mapping[kolom] = i
+					break
+				}
+			}
+		}
+	}
+	return mapping
+}
+
+// parseMapping membaca field form 'mapping' berupa JSON {"kolom": "Header di file"}.
+// Tanpa field tersebut dipakai mapping otomatis dari header.
+func parseMapping(raw string, header []string) (map[string]int, error) {
+	mapping := suggestMapping(header)
+	if raw == "" {
+		return mapping, nil
+	}
+
+	var manual map[string]string
+	if err := json.Unmarshal([]byte(raw), &manual); err != nil {
+		return nil, errors.New("mapping harus JSON {\"kolom\": \"header\"}")
+	}
+	for kolom, h := range manual {
+		if _, ok := importKolom[kolom]; !ok {
+			return nil, fmt.Errorf("kolom '%s' tidak dikenal", kolom)
+		}
+		idx := -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(h)) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("header '%s' tidak ada di file", h)
+		}
+		mapping[kolom] = idx
+	}
+	return mapping, nil
+}
+
+// rowToAlumni membentuk alumni dari satu baris sesuai mapping
+func rowToAlumni(row []string, mapping map[string]int) (*model.Alumni, error) {
+	cell := func(kolom string) string {
+		i, ok := mapping[kolom]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+	tahun := func(kolom string) (int, error) {
+		v := cell(kolom)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("%s '%s' harus angka", kolom, v)
+		}
+		return n, nil
+	}
+
+	a := &model.Alumni{
+		NIM:       cell("nim"),
+		Nama:      cell("nama"),
+		Jurusan:   cell("jurusan"),
+		Email:     cell("email"),
+		NoTelepon: cell("no_telepon"),
+		Alamat:    cell("alamat"),
+	}
+	var err error
+	if a.Angkatan, err = tahun("angkatan"); err != nil {
+		return nil, err
+	}
+	if a.TahunLulus, err = tahun("tahun_lulus"); err != nil {
+		return nil, err
+	}
+	if err := validateAlumni(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ------------------- Proses Import -------------------
+
+// run memproses semua baris data dan mengisi laporan job
+func (s *AlumniImportService) run(ctx context.Context, job *model.ImportJob, rows [][]string, mapping map[string]int) {
+	// Hanya kolom yang ada di file yang menimpa data alumni lama
+	fields := make([]string, 0, len(mapping))
+	for kolom := range mapping {
+		fields = append(fields, kolom)
+	}
+
+	seen := map[string]int{}
+	for i, row := range rows {
+		baris := i + 2 // baris 1 adalah header
+		res := model.ImportRow{Baris: baris}
+
+		a, err := rowToAlumni(row, mapping)
+		switch {
+		case err != nil:
+			res.Status, res.Alasan = model.ImportRejected, err.Error()
+		case seen[a.NIM] != 0:
+			res.NIM = a.NIM
+			res.Status, res.Alasan = model.ImportRejected, fmt.Sprintf("NIM duplikat dengan baris %d", seen[a.NIM])
+		default:
+			res.NIM = a.NIM
+			seen[a.NIM] = baris
+			res.Status, err = s.save(ctx, a, fields, job.DryRun)
+			if err != nil {
+				res.Status, res.Alasan = model.ImportRejected, "gagal menyimpan: "+err.Error()
+			}
+		}
+
+		switch res.Status {
+		case model.ImportInserted:
+			job.Inserted++
+		case model.ImportUpdated:
+			job.Updated++
+		default:
+			job.Rejected++
+		}
+		if len(job.Rows) < importMaxRows {
+			job.Rows = append(job.Rows, res)
+		} else {
+			job.RowsTruncated = true
+		}
+	}
+	if !job.DryRun && job.Inserted+job.Updated > 0 {
+		s.cache.Invalidate()
+	}
+}
+
+// save melakukan upsert berdasarkan NIM; pada dry-run hanya dicek apakah NIM sudah ada
+func (s *AlumniImportService) save(ctx context.Context, a *model.Alumni, fields []string, dryRun bool) (string, error) {
+	if dryRun {
+		existing, err := s.repo.GetByNIM(ctx, a.NIM)
+		if err != nil {
+			return "", err
+		}
+		if existing != nil {
+			return model.ImportUpdated, nil
+		}
+		return model.ImportInserted, nil
+	}
+
+	a.CreatedAt = time.Now()
+	a.UpdatedAt = a.CreatedAt
+	inserted, err := s.repo.UpsertByNIM(ctx, a, fields)
+	if err != nil {
+		return "", err
+	}
+	if inserted {
+		return model.ImportInserted, nil
+	}
+	return model.ImportUpdated, nil
+}
+
+// runBackground memproses import besar dan menyimpan hasilnya ke import_jobs
+func (s *AlumniImportService) runBackground(job *model.ImportJob, rows [][]string, mapping map[string]int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	job.Status = model.JobRunning
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		log.Println("Gagal memperbarui job import:", err)
+	}
+
+	s.run(ctx, job, rows, mapping)
+
+	now := time.Now()
+	job.Status = model.JobDone
+	job.FinishedAt = &now
+	if err := ctx.Err(); err != nil {
+		job.Status = model.JobFailed
+		job.Error = err.Error()
+	}
+	if err := s.jobRepo.Update(context.Background(), job); err != nil {
+		log.Println("Gagal menyimpan hasil job import:", err)
+	}
+}
+
+// FailInterrupted menandai job import yang terputus karena server berhenti
+func (s *AlumniImportService) FailInterrupted(ctx context.Context) error {
+	n, err := s.jobRepo.FailRunning(ctx, "server berhenti sebelum import selesai, upload ulang file untuk melanjutkan")
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("%d job import yang terputus ditandai gagal", n)
+	}
+	return nil
+}
+
+// ------------------- Handler -------------------
+
+// Preview menampilkan header, mapping otomatis, dan beberapa baris contoh
+// sebagai langkah pemetaan kolom sebelum import
+func (s *AlumniImportService) Preview(c *fiber.Ctx) error {
+	fileName, rows, err := readImportFile(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	header := rows[0]
+	mapping := map[string]string{}
+	for kolom, i := range suggestMapping(header) {
+		mapping[kolom] = header[i]
+	}
+
+	contoh := rows[1:]
+	if len(contoh) > 5 {
+		contoh = contoh[:5]
+	}
+
+	kolom := make([]string, 0, len(importKolom))
+	for k := range importKolom {
+		kolom = append(kolom, k)
+	}
+
+	return c.JSON(fiber.Map{
+		"file_name":  fileName,
+		"header":     header,
+		"kolom":      kolom,
+		"mapping":    mapping,
+		"contoh":     contoh,
+		"total_rows": len(rows) - 1,
+	})
+}
+
+// Import mengimpor alumni dari CSV / XLSX (field 'file').
+// Form: mapping (JSON opsional), dry_run=true. File besar diproses di background (202).
+func (s *AlumniImportService) Import(c *fiber.Ctx) error {
+	fileName, rows, err := readImportFile(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	mapping, err := parseMapping(c.FormValue("mapping"), rows[0])
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, wajib := range []string{"nim", "nama"} {
+		if _, ok := mapping[wajib]; !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Kolom " + wajib + " tidak ditemukan, atur lewat field mapping"})
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run", c.Query("dry_run")))
+	userID, _ := c.Locals("user_id").(string)
+	data := rows[1:]
+
+	job := &model.ImportJob{
+		Status:    model.JobPending,
+		DryRun:    dryRun,
+		FileName:  fileName,
+		CreatedBy: userID,
+		Total:     len(data),
+		Rows:      []model.ImportRow{},
+		CreatedAt: time.Now(),
+	}
+
+	if len(data) > importSyncLimit {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if _, err := s.jobRepo.Create(ctx, job); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat job import", "detail": err.Error()})
+		}
+		go s.runBackground(job, data, mapping)
+		return c.Status(202).JSON(fiber.Map{
+			"message": "Import diproses di background",
+			"job_id":  job.ID.Hex(),
+			"total":   job.Total,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	s.run(ctx, job, data, mapping)
+	now := time.Now()
+	job.Status = model.JobDone
+	job.FinishedAt = &now
+	return c.JSON(job)
+}
+
+// GetJob mengembalikan status dan laporan job import
+func (s *AlumniImportService) GetJob(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := s.jobRepo.GetByID(ctx, c.Params("jobId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if job == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Job import tidak ditemukan"})
+	}
+	return c.JSON(job)
+}
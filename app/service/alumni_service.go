@@ -2,35 +2,71 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"praktikummongo/app/model"
 	"praktikummongo/app/repository"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson" // bson masih dipakai di GetAlumniDenganDuaPekerjaan
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AlumniService struct {
-	repo      repository.IAlumniRepository
-	pekerjaan *mongo.Collection
+	repo  repository.IAlumniRepository
+	cache *StatsCacheService
 }
 
-func NewAlumniService(repo repository.IAlumniRepository, db *mongo.Database) *AlumniService {
-	return &AlumniService{
-		repo:      repo,
-		pekerjaan: db.Collection("pekerjaan_alumni"),
-	}
+func NewAlumniService(repo repository.IAlumniRepository, cache *StatsCacheService) *AlumniService {
+	s := &AlumniService{repo: repo, cache: cache}
+	cache.Register("alumni.jumlah_angkatan", nil, s.computeJumlahByAngkatan)
+	cache.Register("alumni.jumlah_pekerjaan", []string{"min", "max", "page", "limit"}, s.computeByJumlahPekerjaan)
+	return s
 }
 
 // ------------------- CRUD -------------------
 
+// Relasi yang bisa disertakan lewat ?expand=. File alumni tidak termasuk karena
+// harus lewat cek akses; pakai GET /api/alumni/:id/files
+var alumniExpandOptions = map[string]bool{"pekerjaan": true}
+
+// parseExpand membaca ?expand=a,b dan menolak relasi yang tidak dikenal
+func parseExpand(c *fiber.Ctx) ([]string, error) {
+	raw := c.Query("expand")
+	if raw == "" {
+		return nil, nil
+	}
+	var expand []string
+	for _, e := range strings.Split(raw, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if !alumniExpandOptions[e] {
+			return nil, fmt.Errorf("expand '%s' tidak didukung", e)
+		}
+		expand = append(expand, e)
+	}
+	return expand, nil
+}
+
 func (s *AlumniService) GetAll(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	expand, err := parseExpand(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(expand) > 0 {
+		list, err := s.repo.GetAllExpanded(ctx, expand)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+		}
+		return c.JSON(list)
+	}
+
 	list, err := s.repo.GetAll(ctx)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
@@ -43,6 +79,21 @@ func (s *AlumniService) GetByID(c *fiber.Ctx) error {
 	defer cancel()
 
 	id := c.Params("id")
+	expand, err := parseExpand(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(expand) > 0 {
+		detail, err := s.repo.GetByIDExpanded(ctx, id, expand)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+		}
+		if detail == nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
+		}
+		return c.JSON(detail)
+	}
+
 	alumni, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
@@ -61,6 +112,9 @@ func (s *AlumniService) Create(c *fiber.Ctx) error {
 	if err := c.BodyParser(&a); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Input tidak valid", "detail": err.Error()})
 	}
+	if err := validateAlumni(&a); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Input tidak valid", "detail": err.Error()})
+	}
 
 	a.CreatedAt = time.Now()
 	a.UpdatedAt = time.Now()
@@ -69,6 +123,7 @@ func (s *AlumniService) Create(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan data", "detail": err.Error()})
 	}
+	s.cache.Invalidate()
 	return c.Status(201).JSON(newAlumni)
 }
 
@@ -86,6 +141,7 @@ func (s *AlumniService) Update(c *fiber.Ctx) error {
 	if err := s.repo.Update(ctx, id, &a); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal memperbarui data", "detail": err.Error()})
 	}
+	s.cache.Invalidate()
 	return c.JSON(fiber.Map{"message": "Alumni berhasil diupdate"})
 }
 
@@ -97,11 +153,25 @@ func (s *AlumniService) Delete(c *fiber.Ctx) error {
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus data", "detail": err.Error()})
 	}
+	s.cache.Invalidate()
 	return c.JSON(fiber.Map{"message": "Alumni berhasil dihapus"})
 }
 
 // ------------------- Pagination + Filter -------------------
 
+// Parameter query yang dibaca parseAlumniFilter
+var alumniFilterParams = []string{"search", "jurusan", "angkatan", "tahun_lulus"}
+
+// parseAlumniFilter membaca query filter alumni: search, jurusan, angkatan, tahun_lulus
+func parseAlumniFilter(c queryParams) model.AlumniFilter {
+	return model.AlumniFilter{
+		Search:     c.Query("search", ""),
+		Jurusan:    c.Query("jurusan", ""),
+		Angkatan:   c.QueryInt("angkatan", 0),
+		TahunLulus: c.QueryInt("tahun_lulus", 0),
+	}
+}
+
 func (s *AlumniService) GetAlumniWithPagination(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -110,12 +180,11 @@ func (s *AlumniService) GetAlumniWithPagination(c *fiber.Ctx) error {
 	limitStr := c.Query("limit", "10")
 	sortBy := c.Query("sort", "nama")
 	order := c.Query("order", "asc")
-	search := c.Query("search", "")
 
 	page, _ := strconv.Atoi(pageStr)
 	limit, _ := strconv.Atoi(limitStr)
 
-	items, total, err := s.repo.GetWithFilter(ctx, page, limit, sortBy, order, search)
+	items, total, err := s.repo.GetWithFilter(ctx, page, limit, sortBy, order, parseAlumniFilter(c))
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
 	}
@@ -135,67 +204,56 @@ func (s *AlumniService) GetAlumniWithPagination(c *fiber.Ctx) error {
 }
 
 // ------------------- Statistik -------------------
+// Statistik disajikan dari stats_cache lewat StatsCacheService.
 
-// --- FUNGSI INI DIPERBARUI ---
 func (s *AlumniService) GetJumlahByAngkatan(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	// HAPUS SEMUA LOGIKA AGREGASI DARI SINI
-	// ... (groupStage, sortStage, cursor, dll dihapus) ...
+	return s.cache.Serve(c, "alumni.jumlah_angkatan")
+}
 
-	// CUKUP PANGGIL REPOSITORY
+func (s *AlumniService) computeJumlahByAngkatan(ctx context.Context, q queryParams) (fiber.Map, error) {
 	results, err := s.repo.GetJumlahByAngkatan(ctx)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+		return nil, err
 	}
+	return fiber.Map{"data": results}, nil
+}
 
-	// HAPUS DECODE LOGIC DAN STRUCT LOKAL
-	// ... (cursor.All, struct Result, dll dihapus) ...
-
-	// LANGSUNG KEMBALIKAN HASIL DARI REPO
-	return c.JSON(results)
+// GetByJumlahPekerjaan - Laporan alumni berdasarkan jumlah pekerjaan aktif.
+// Query: min (default 2), max (0 = tanpa batas), page, limit
+func (s *AlumniService) GetByJumlahPekerjaan(c *fiber.Ctx) error {
+	return s.cache.Serve(c, "alumni.jumlah_pekerjaan")
 }
-// --- END PERBAIKAN ---
 
-func (s *AlumniService) GetAlumniDenganDuaPekerjaan(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (s *AlumniService) computeByJumlahPekerjaan(ctx context.Context, q queryParams) (fiber.Map, error) {
+	min := q.QueryInt("min", 2)
+	max := q.QueryInt("max", 0)
+	page := q.QueryInt("page", 1)
+	limit := q.QueryInt("limit", 10)
+	if min < 0 || max < 0 || (max > 0 && max < min) {
+		return nil, statsParamError{msg: "Parameter min/max tidak valid"}
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
 
-	// Fungsi ini tidak error karena s.pekerjaan memang field publik di AlumniService
-	pipeline := mongo.Pipeline{
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$alumni_id"},
-			{Key: "jumlah_pekerjaan", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-		{{Key: "$match", Value: bson.D{{Key: "jumlah_pekerjaan", Value: bson.D{{Key: "$gte", Value: 2}}}}}},
-		{{Key: "$lookup", Value: bson.D{
-			{Key: "from", Value: "alumni"},
-			{Key: "localField", Value: "_id"},
-			{Key: "foreignField", Value: "_id"},
-			{Key: "as", Value: "alumni"},
-		}}},
-		{{Key: "$unwind", Value: "$alumni"}},
-		{{Key: "$project", Value: bson.D{
-			{Key: "nama", Value: "$alumni.nama"},
-			{Key: "jumlah_pekerjaan", Value: 1},
-		}}},
-	}
-
-	cursor, err := s.pekerjaan.Aggregate(ctx, pipeline)
+	items, total, err := s.repo.GetByJumlahPekerjaan(ctx, min, max, page, limit)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	type Result struct {
-		Nama            string `bson:"nama" json:"nama"`
-		JumlahPekerjaan int    `bson:"jumlah_peekerjaan" json:"jumlah_pekerjaan"` // Typo di bson tag? saya biarkan sesuai kode Anda
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + limit - 1) / limit
 	}
 
-	var results []Result
-	if err := cursor.All(ctx, &results); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal baca data", "detail": err.Error()})
-	}
-	return c.JSON(results)
-}
\ No newline at end of file
+	return fiber.Map{
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+		"total_pages": totalPages,
+		"data":        items,
+	}, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeAlumniRepo hanya mengimplementasikan GetByJumlahPekerjaan; method lain panic
+type fakeAlumniRepo struct {
+	repository.IAlumniRepository
+
+	items []model.AlumniJumlahPekerjaan
+	total int
+	calls [][4]int // min, max, page, limit
+}
+
+func (r *fakeAlumniRepo) GetByJumlahPekerjaan(ctx context.Context, min, max, page, limit int) ([]model.AlumniJumlahPekerjaan, int, error) {
+	r.calls = append(r.calls, [4]int{min, max, page, limit})
+	return r.items, r.total, nil
+}
+
+func jumlahPekerjaanApp(repo *fakeAlumniRepo) *fiber.App {
+	s := NewAlumniService(repo, NewStatsCacheService(newFakeStatsCacheRepo(), 0, 0))
+	app := fiber.New()
+	app.Get("/alumni/jumlah-pekerjaan", s.GetByJumlahPekerjaan)
+	return app
+}
+
+func getJSON(t *testing.T, app *fiber.App, url string, out interface{}) int {
+	t.Helper()
+	return doJSON(t, app, "GET", url, out)
+}
+
+func postJSON(t *testing.T, app *fiber.App, url string, out interface{}) int {
+	t.Helper()
+	return doJSON(t, app, "POST", url, out)
+}
+
+func doJSON(t *testing.T, app *fiber.App, method, url string, out interface{}) int {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(method, url, nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			t.Fatalf("%s: body bukan JSON: %s", url, body)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestGetByJumlahPekerjaanResponse(t *testing.T) {
+	id := primitive.NewObjectID()
+	repo := &fakeAlumniRepo{
+		items: []model.AlumniJumlahPekerjaan{{ID: id, NIM: "A001", Nama: "Andi", JumlahPekerjaan: 3}},
+		total: 21,
+	}
+	app := jumlahPekerjaanApp(repo)
+
+	var body struct {
+		Page       int                      `json:"page"`
+		Limit      int                      `json:"limit"`
+		Total      int                      `json:"total"`
+		TotalPages int                      `json:"total_pages"`
+		Data       []map[string]interface{} `json:"data"`
+	}
+	if status := getJSON(t, app, "/alumni/jumlah-pekerjaan?min=3&max=5&page=2&limit=10", &body); status != 200 {
+		t.Fatalf("status = %d, want 200", status)
+	}
+
+	if len(repo.calls) != 1 || repo.calls[0] != [4]int{3, 5, 2, 10} {
+		t.Errorf("repo dipanggil dengan %v, want [3 5 2 10]", repo.calls)
+	}
+	if body.Page != 2 || body.Limit != 10 || body.Total != 21 || body.TotalPages != 3 {
+		t.Errorf("paginasi = %+v, want page 2, limit 10, total 21, total_pages 3", body)
+	}
+	if len(body.Data) != 1 {
+		t.Fatalf("data = %v, want 1 baris", body.Data)
+	}
+	row := body.Data[0]
+	if row["id"] != id.Hex() || row["nim"] != "A001" || row["nama"] != "Andi" || row["jumlah_pekerjaan"] != float64(3) {
+		t.Errorf("baris = %v, want id, nim, nama, dan jumlah_pekerjaan", row)
+	}
+}
+
+func TestGetByJumlahPekerjaanDefault(t *testing.T) {
+	repo := &fakeAlumniRepo{items: []model.AlumniJumlahPekerjaan{}}
+	app := jumlahPekerjaanApp(repo)
+
+	var body fiber.Map
+	if status := getJSON(t, app, "/alumni/jumlah-pekerjaan?page=0&limit=-1", &body); status != 200 {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	// min default 2, max 0 (tanpa batas), halaman dan limit tidak valid kembali ke default
+	if len(repo.calls) != 1 || repo.calls[0] != [4]int{2, 0, 1, 10} {
+		t.Errorf("repo dipanggil dengan %v, want [2 0 1 10]", repo.calls)
+	}
+	if data, ok := body["data"].([]interface{}); !ok || len(data) != 0 {
+		t.Errorf("data = %v, want array kosong", body["data"])
+	}
+}
+
+func TestGetByJumlahPekerjaanBatasTidakValid(t *testing.T) {
+	for _, url := range []string{
+		"/alumni/jumlah-pekerjaan?min=-1",
+		"/alumni/jumlah-pekerjaan?max=-2",
+		"/alumni/jumlah-pekerjaan?min=5&max=3",
+	} {
+		repo := &fakeAlumniRepo{}
+		var body fiber.Map
+		if status := getJSON(t, jumlahPekerjaanApp(repo), url, &body); status != 400 {
+			t.Errorf("%s: status = %d, want 400", url, status)
+		}
+		if len(repo.calls) != 0 {
+			t.Errorf("%s: repo tidak boleh dipanggil, got %v", url, repo.calls)
+		}
+	}
+}
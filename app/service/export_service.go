@@ -0,0 +1,386 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Batas waktu satu export; data dialirkan langsung dari cursor ke response
+const exportTimeout = 30 * time.Minute
+
+// exportColumn adalah satu kolom export beserta cara mengambil nilainya
+type exportColumn[T any] struct {
+	Name  string
+	Value func(*T) interface{}
+}
+
+// waktuOpsional mengubah *time.Time nil menjadi sel kosong
+func waktuOpsional(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+var alumniExportColumns = []exportColumn[model.Alumni]{
+	{"id", func(a *model.Alumni) interface{} { return a.ID.Hex() }},
+	{"nim", func(a *model.Alumni) interface{} { return a.NIM }},
+	{"nama", func(a *model.Alumni) interface{} { return a.Nama }},
+	{"jurusan", func(a *model.Alumni) interface{} { return a.Jurusan }},
+	{"angkatan", func(a *model.Alumni) interface{} { return a.Angkatan }},
+	{"tahun_lulus", func(a *model.Alumni) interface{} { return a.TahunLulus }},
+	{"email", func(a *model.Alumni) interface{} { return a.Email }},
+	{"no_telepon", func(a *model.Alumni) interface{} { return a.NoTelepon }},
+	{"alamat", func(a *model.Alumni) interface{} { return a.Alamat }},
+	{"created_at", func(a *model.Alumni) interface{} { return a.CreatedAt }},
+	{"updated_at", func(a *model.Alumni) interface{} { return a.UpdatedAt }},
+}
+
+// gajiKolom mengambil satu bagian gaji; pekerjaan tanpa gaji menjadi sel kosong
+func gajiKolom(get func(*model.Gaji) interface{}) func(*model.PekerjaanAlumni) interface{} {
+	return func(p *model.PekerjaanAlumni) interface{} {
+		if p.Gaji == nil {
+			return nil
+		}
+		return get(p.Gaji)
+	}
+}
+
+var pekerjaanExportColumns = []exportColumn[model.PekerjaanAlumni]{
+	{"id", func(p *model.PekerjaanAlumni) interface{} { return p.ID.Hex() }},
+	{"alumni_id", func(p *model.PekerjaanAlumni) interface{} { return p.AlumniID.Hex() }},
+	{"nama_perusahaan", func(p *model.PekerjaanAlumni) interface{} { return p.NamaPerusahaan }},
+	{"posisi_jabatan", func(p *model.PekerjaanAlumni) interface{} { return p.PosisiJabatan }},
+	{"bidang_industri", func(p *model.PekerjaanAlumni) interface{} { return p.BidangIndustri }},
+	{"lokasi_kerja", func(p *model.PekerjaanAlumni) interface{} { return p.LokasiKerja }},
+	{"gaji_min", gajiKolom(func(g *model.Gaji) interface{} { return g.Min })},
+	{"gaji_max", gajiKolom(func(g *model.Gaji) interface{} { return g.Max })},
+	{"gaji_currency", gajiKolom(func(g *model.Gaji) interface{} { return g.Currency })},
+	{"gaji_period", gajiKolom(func(g *model.Gaji) interface{} { return g.Period })},
+	{"tanggal_mulai_kerja", func(p *model.PekerjaanAlumni) interface{} { return p.TanggalMulaiKerja }},
+	{"tanggal_selesai_kerja", func(p *model.PekerjaanAlumni) interface{} { return waktuOpsional(p.TanggalSelesaiKerja) }},
+	{"status_pekerjaan", func(p *model.PekerjaanAlumni) interface{} { return p.StatusPekerjaan }},
+	{"deskripsi_pekerjaan", func(p *model.PekerjaanAlumni) interface{} { return p.DeskripsiPekerjaan }},
+	{"created_at", func(p *model.PekerjaanAlumni) interface{} { return p.CreatedAt }},
+	{"updated_at", func(p *model.PekerjaanAlumni) interface{} { return p.UpdatedAt }},
+	{"is_deleted", func(p *model.PekerjaanAlumni) interface{} { return waktuOpsional(p.IsDeleted) }},
+}
+
+// selectColumns membaca ?columns=a,b; kosong berarti semua kolom
+func selectColumns[T any](raw string, all []exportColumn[T]) ([]exportColumn[T], error) {
+	if strings.TrimSpace(raw) == "" {
+		return all, nil
+	}
+	byName := make(map[string]exportColumn[T], len(all))
+	for _, col := range all {
+		byName[col.Name] = col
+	}
+
+	var cols []exportColumn[T]
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		col, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("kolom '%s' tidak dikenal", name)
+		}
+		cols = append(cols, col)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("minimal satu kolom harus dipilih")
+	}
+	return cols, nil
+}
+
+// ------------------- Writer -------------------
+
+// exportWriter menulis baris export ke response dalam satu format.
+// Close menyelesaikan export; Abort membuang sumber daya jika export gagal di tengah.
+type exportWriter interface {
+	Header(names []string) error
+	Row(values []interface{}) error
+	Close() error
+	Abort()
+}
+
+var exportFormats = map[string]struct {
+	ContentType string
+	New         func(w *bufio.Writer) (exportWriter, error)
+}{
+	"csv":    {"text/csv; charset=utf-8", newCSVExport},
+	"ndjson": {"application/x-ndjson", newNDJSONExport},
+	"xlsx":   {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", newXLSXExport},
+}
+
+// formatSel mengubah nilai menjadi teks untuk CSV
+func formatSel(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return x.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// sanitizeSelCSV menambahkan ' di depan teks yang diawali =, +, -, @ (atau tab / CR)
+// agar tidak dijalankan sebagai formula saat CSV dibuka di spreadsheet
+func sanitizeSelCSV(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type csvExport struct {
+	w *csv.Writer
+}
+
+func newCSVExport(w *bufio.Writer) (exportWriter, error) {
+	// BOM agar Excel membaca UTF-8 dengan benar
+	if _, err := w.WriteString("\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	return &csvExport{w: csv.NewWriter(w)}, nil
+}
+
+func (e *csvExport) Header(names []string) error {
+	return e.w.Write(names)
+}
+
+func (e *csvExport) Row(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatSel(v)
+		// Hanya teks; angka negatif tetap angka
+		if _, ok := v.(string); ok {
+			record[i] = sanitizeSelCSV(record[i])
+		}
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExport) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExport) Abort() {}
+
+type ndjsonExport struct {
+	w     *bufio.Writer
+	names []string
+}
+
+func newNDJSONExport(w *bufio.Writer) (exportWriter, error) {
+	return &ndjsonExport{w: w}, nil
+}
+
+func (e *ndjsonExport) Header(names []string) error {
+	e.names = names
+	return nil
+}
+
+// Row menulis satu objek JSON per baris dengan urutan kolom sesuai pilihan
+func (e *ndjsonExport) Row(values []interface{}) error {
+	e.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.names[i])
+		val, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		e.w.Write(key)
+		e.w.WriteByte(':')
+		e.w.Write(val)
+	}
+	e.w.WriteString("}\n")
+	return nil
+}
+
+func (e *ndjsonExport) Close() error {
+	return nil
+}
+
+func (e *ndjsonExport) Abort() {}
+
+// xlsxExport memakai StreamWriter excelize yang menyimpan baris ke file
+// sementara, sehingga memori tetap kecil untuk export besar
+type xlsxExport struct {
+	out *bufio.Writer
+	f   *excelize.File
+	sw  *excelize.StreamWriter
+	row int
+}
+
+func newXLSXExport(w *bufio.Writer) (exportWriter, error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter("Sheet1")
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxExport{out: w, f: f, sw: sw}, nil
+}
+
+func (e *xlsxExport) Header(names []string) error {
+	cells := make([]interface{}, len(names))
+	for i, n := range names {
+		cells[i] = n
+	}
+	return e.Row(cells)
+}
+
+func (e *xlsxExport) Row(values []interface{}) error {
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.sw.SetRow(cell, values)
+}
+
+func (e *xlsxExport) Close() error {
+	defer e.f.Close()
+	if err := e.sw.Flush(); err != nil {
+		return err
+	}
+	return e.f.Write(e.out)
+}
+
+// Abort menutup workbook sehingga file sementara StreamWriter ikut dihapus
+func (e *xlsxExport) Abort() {
+	if err := e.f.Close(); err != nil {
+		log.Println("Gagal menghapus file sementara export xlsx:", err)
+	}
+}
+
+// ------------------- Streaming -------------------
+
+// streamExport membuka cursor lalu mengalirkan dokumen satu per satu ke response.
+// Error sebelum streaming dikirim sebagai JSON; error di tengah streaming hanya bisa dicatat di log.
+func streamExport[T any](c *fiber.Ctx, name string, all []exportColumn[T], open func(ctx context.Context) (*mongo.Cursor, error)) error {
+	format := strings.ToLower(c.Query("format", "csv"))
+	f, ok := exportFormats[format]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "format harus csv, xlsx, atau ndjson"})
+	}
+	cols, err := selectColumns(c.Query("columns"), all)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	cursor, err := open(ctx)
+	if err != nil {
+		cancel()
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+
+	fileName := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format)
+	c.Set(fiber.HeaderContentType, f.ContentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+fileName+`"`)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		defer cursor.Close(ctx)
+
+		if err := writeExport(ctx, w, f.New, cursor, cols); err != nil {
+			log.Printf("Export %s terhenti: %v", name, err)
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+func writeExport[T any](ctx context.Context, w *bufio.Writer, newWriter func(*bufio.Writer) (exportWriter, error), cursor *mongo.Cursor, cols []exportColumn[T]) error {
+	ew, err := newWriter(w)
+	if err != nil {
+		return err
+	}
+	if err := writeRows(ctx, ew, cursor, cols); err != nil {
+		ew.Abort()
+		return err
+	}
+	return ew.Close()
+}
+
+// writeRows menulis header lalu satu baris per dokumen dari cursor
+func writeRows[T any](ctx context.Context, ew exportWriter, cursor *mongo.Cursor, cols []exportColumn[T]) error {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.Name
+	}
+	if err := ew.Header(names); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(cols))
+	for cursor.Next(ctx) {
+		var doc T
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		for i, col := range cols {
+			values[i] = col.Value(&doc)
+		}
+		if err := ew.Row(values); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// ------------------- Handler -------------------
+
+// ExportService mengekspor data alumni dan pekerjaan ke CSV / XLSX / NDJSON
+type ExportService struct {
+	alumniRepo    repository.IAlumniRepository
+	pekerjaanRepo repository.IPekerjaanRepository
+}
+
+func NewExportService(alumniRepo repository.IAlumniRepository, pekerjaanRepo repository.IPekerjaanRepository) *ExportService {
+	return &ExportService{alumniRepo: alumniRepo, pekerjaanRepo: pekerjaanRepo}
+}
+
+// ExportAlumni - Query: format=csv|xlsx|ndjson, columns=nim,nama,..., filter alumni
+func (s *ExportService) ExportAlumni(c *fiber.Ctx) error {
+	filter := parseAlumniFilter(c)
+	return streamExport(c, "alumni", alumniExportColumns, func(ctx context.Context) (*mongo.Cursor, error) {
+		return s.alumniRepo.Export(ctx, filter)
+	})
+}
+
+// ExportPekerjaan - Query: format, columns, aktif_pada, mulai_dari, mulai_sampai, include_deleted
+func (s *ExportService) ExportPekerjaan(c *fiber.Ctx) error {
+	includeDeleted, allowed := includeDeletedQuery(c)
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Hanya admin yang boleh melihat data terhapus"})
+	}
+	filter, err := parsePekerjaanFilter(c, includeDeleted)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return streamExport(c, "pekerjaan", pekerjaanExportColumns, func(ctx context.Context) (*mongo.Cursor, error) {
+		return s.pekerjaanRepo.Export(ctx, filter)
+	})
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+	"praktikummongo/app/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Objek yang dikarantina dipindah ke prefix ini dan tidak ikut diperiksa lagi
+const quarantinePrefix = "quarantine/"
+
+// FileConsistencyService mencocokkan koleksi files / blobs dengan isi storage:
+// metadata tanpa isi, isi tanpa metadata, ukuran yang berbeda, dan ref_count
+// blob yang salah. Worker berkala hanya membuat laporan; perbaikan lewat admin.
+type FileConsistencyService struct {
+	files    repository.FileRepository
+	blobs    repository.IBlobRepository
+	uploads  repository.IUploadSessionRepository
+	store    storage.Storage
+	interval time.Duration
+
+	mu   sync.Mutex
+	last *model.ConsistencyReport
+}
+
+func NewFileConsistencyService(files repository.FileRepository, blobs repository.IBlobRepository,
+	uploads repository.IUploadSessionRepository, store storage.Storage, interval time.Duration) *FileConsistencyService {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return &FileConsistencyService{files: files, blobs: blobs, uploads: uploads, store: store, interval: interval}
+}
+
+// Start menjalankan pemeriksaan (tanpa perbaikan) di background sampai ctx dibatalkan
+func (s *FileConsistencyService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := s.Check(ctx, model.ConsistencyActionReport, true, time.Hour)
+				if err != nil {
+					log.Println("Pemeriksaan konsistensi file gagal:", err)
+					continue
+				}
+				if len(report.Issues) > 0 {
+					log.Printf("Pemeriksaan konsistensi file: %d masalah ditemukan %v", len(report.Issues), report.Summary)
+				}
+			}
+		}
+	}()
+}
+
+// minFixAge adalah min_age terkecil untuk quarantine / clean. Files dibaca sebelum
+// storage di-list, jadi upload yang masuk di antaranya terlihat yatim; objek yang
+// lebih muda dari batas ini tidak pernah dipindah atau dihapus.
+const minFixAge = 10 * time.Minute
+
+// Check memeriksa seluruh file. action menentukan perbaikan (report, quarantine,
+// clean); dryRun hanya mencatat tindakan yang akan dilakukan. Objek storage yang
+// lebih muda dari minAge dilewati karena bisa jadi upload yang sedang berjalan.
+func (s *FileConsistencyService) Check(ctx context.Context, action string, dryRun bool, minAge time.Duration) (*model.ConsistencyReport, error) {
+	if action != model.ConsistencyActionReport && minAge < minFixAge {
+		minAge = minFixAge
+	}
+	report := &model.ConsistencyReport{
+		Action:    action,
+		DryRun:    dryRun,
+		StartedAt: time.Now(),
+		Summary:   map[string]int{},
+		Issues:    []model.ConsistencyIssue{},
+	}
+	fix := !dryRun && action != model.ConsistencyActionReport
+
+	files, err := s.files.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := s.blobs.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.store.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	report.Files, report.Blobs, report.Objects = len(files), len(blobs), len(objects)
+
+	stored := make(map[string]storage.Object, len(objects))
+	for _, obj := range objects {
+		stored[obj.Key] = obj
+	}
+	referenced := map[string]bool{}
+	refs := map[string]int{}
+
+	add := func(issue model.ConsistencyIssue) {
+		report.Summary[issue.Type]++
+		report.Issues = append(report.Issues, issue)
+	}
+
+	// Metadata file: isi harus ada dan ukurannya cocok
+	for i := range files {
+		f := &files[i]
+		if f.SHA256 != "" {
+			refs[f.SHA256]++
+		}
+
+		keys := map[string]int64{f.Key(): f.FileSize}
+		for _, v := range f.Variants {
+			keys[v.StorageKey] = v.FileSize
+		}
+		first := len(report.Issues)
+		missing, changed := false, false
+		for key, size := range keys {
+			referenced[key] = true
+			obj, ok := stored[key]
+			if !ok && key == f.Key() {
+				missing = true
+				add(model.ConsistencyIssue{Type: model.IssueMissingBlob, Key: key, FileID: &f.ID, SHA256: f.SHA256,
+					Action: actionFor(action, model.ConsistencyActionClean, "deleted")})
+				continue
+			}
+			if !ok {
+				// Variant yang hilang cukup dilepas dari metadata
+				add(model.ConsistencyIssue{Type: model.IssueMissingBlob, Key: key, FileID: &f.ID,
+					Action: actionFor(action, model.ConsistencyActionClean, "fixed")})
+				changed = true
+				for name, v := range f.Variants {
+					if v.StorageKey == key {
+						delete(f.Variants, name)
+					}
+				}
+				continue
+			}
+			if obj.Size == size {
+				continue
+			}
+			add(model.ConsistencyIssue{Type: model.IssueSizeMismatch, Key: key, FileID: &f.ID,
+				Expected: size, Actual: obj.Size, Action: actionFor(action, model.ConsistencyActionClean, "fixed")})
+			changed = true
+			if key == f.Key() {
+				f.FileSize = obj.Size
+			}
+			for name, v := range f.Variants {
+				if v.StorageKey == key {
+					v.FileSize = obj.Size
+					f.Variants[name] = v
+				}
+			}
+		}
+
+		if !fix || action != model.ConsistencyActionClean || (!missing && !changed) {
+			continue
+		}
+		// Isi hilang: metadata dihapus; ref_count blob diperbaiki di tahap berikut
+		if missing {
+			err = s.files.Delete(f.ID.Hex())
+			if err == nil && f.SHA256 != "" {
+				refs[f.SHA256]--
+			}
+		} else {
+			err = s.files.Update(f)
+		}
+		if err != nil {
+			for j := first; j < len(report.Issues); j++ {
+				report.Issues[j].Error = err.Error()
+			}
+		}
+	}
+
+	// Blob dedup: ref_count harus sama dengan jumlah file yang menunjuknya
+	for _, b := range blobs {
+		count := refs[b.ID]
+		if count > 0 {
+			referenced[b.StorageKey] = true
+			for _, v := range b.Variants {
+				referenced[v.StorageKey] = true
+			}
+		}
+		if count == b.RefCount {
+			continue
+		}
+
+		issue := model.ConsistencyIssue{Type: model.IssueRefCount, Key: b.StorageKey, SHA256: b.ID,
+			Expected: int64(count), Actual: int64(b.RefCount), Action: actionFor(action, model.ConsistencyActionClean, "fixed")}
+		if fix && action == model.ConsistencyActionClean {
+			// Dihitung ulang tepat sebelum diperbaiki agar upload yang berjalan
+			// selama pemeriksaan tidak ikut terhitung salah. Blob tanpa file
+			// dihapus; isinya ditangani sebagai orphan di bawah.
+			n, err := s.files.CountByHash(b.ID)
+			if err == nil {
+				var ok bool
+				if ok, err = s.blobs.FixRefCount(ctx, b.ID, b.RefCount, int(n)); err == nil && !ok {
+					issue.Action = "skipped"
+				}
+			}
+			if err != nil {
+				issue.Error = err.Error()
+			}
+		}
+		add(issue)
+	}
+
+	// Isi storage tanpa metadata
+	sessions := map[string]bool{}
+	for _, obj := range objects {
+		if referenced[obj.Key] || strings.HasPrefix(obj.Key, quarantinePrefix) || time.Since(obj.ModTime) < minAge {
+			continue
+		}
+		// Potongan upload bertahap masih dipakai selama sesinya ada
+		if strings.HasPrefix(obj.Key, "chunks/") {
+			id := strings.Split(obj.Key, "/")[1]
+			active, ok := sessions[id]
+			if !ok {
+				u, err := s.uploads.GetByID(ctx, id)
+				active = err == nil && u != nil
+				sessions[id] = active
+			}
+			if active {
+				continue
+			}
+		}
+
+		issue := model.ConsistencyIssue{Type: model.IssueOrphanBlob, Key: obj.Key, Actual: obj.Size}
+		var err error
+		switch action {
+		case model.ConsistencyActionQuarantine:
+			issue.Action = "quarantined"
+			if fix {
+				err = storage.Move(ctx, s.store, obj.Key, quarantinePrefix+obj.Key)
+			}
+		case model.ConsistencyActionClean:
+			issue.Action = "deleted"
+			if fix {
+				err = s.store.Delete(ctx, obj.Key)
+			}
+		}
+		if err != nil {
+			issue.Error = err.Error()
+		}
+		add(issue)
+	}
+
+	report.FinishedAt = time.Now()
+	if fix {
+		log.Printf("Perbaikan konsistensi file (%s): %d masalah %v", action, len(report.Issues), report.Summary)
+	}
+
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+	return report, nil
+}
+
+// actionFor mengisi tindakan pada laporan hanya untuk action yang menanganinya
+func actionFor(action, handledBy, label string) string {
+	if action == handledBy {
+		return label
+	}
+	return ""
+}
+
+// ------------------- Handler Admin -------------------
+
+// RunCheck menjalankan pemeriksaan sekarang.
+// Query: action=report|quarantine|clean (default report), dry_run=true,
+// min_age (default 1h, minimal 10m untuk quarantine / clean)
+func (s *FileConsistencyService) RunCheck(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	action := c.Query("action", model.ConsistencyActionReport)
+	switch action {
+	case model.ConsistencyActionReport, model.ConsistencyActionQuarantine, model.ConsistencyActionClean:
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "action harus report, quarantine, atau clean"})
+	}
+	minAge := time.Hour
+	if v := c.Query("min_age"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "min_age tidak valid, contoh: 30m"})
+		}
+		if action != model.ConsistencyActionReport && d < minFixAge {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("min_age untuk %s minimal %.0fm", action, minFixAge.Minutes())})
+		}
+		minAge = d
+	}
+
+	report, err := s.Check(ctx, action, c.QueryBool("dry_run", false), minAge)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa konsistensi file", "detail": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// LastReport menampilkan hasil pemeriksaan terakhir (termasuk dari worker berkala)
+func (s *FileConsistencyService) LastReport(c *fiber.Ctx) error {
+	s.mu.Lock()
+	report := s.last
+	s.mu.Unlock()
+
+	if report == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Belum ada pemeriksaan yang dijalankan"})
+	}
+	return c.JSON(report)
+}
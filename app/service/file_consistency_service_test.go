@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Perbaikan dengan min_age di bawah minFixAge ditolak sebelum pemeriksaan berjalan
+func TestRunCheckMinAgePerbaikan(t *testing.T) {
+	app := fiber.New()
+	app.Post("/check", func(c *fiber.Ctx) error {
+		return NewFileConsistencyService(nil, nil, nil, nil, 0).RunCheck(c)
+	})
+
+	for _, url := range []string{
+		"/check?action=clean&min_age=0",
+		"/check?action=quarantine&min_age=5m",
+		"/check?action=clean&min_age=-1m",
+	} {
+		var body fiber.Map
+		if status := postJSON(t, app, url, &body); status != 400 {
+			t.Errorf("%s: status = %d, want 400", url, status)
+		}
+	}
+}
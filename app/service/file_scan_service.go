@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+	"praktikummongo/app/scanner"
+	"praktikummongo/app/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FileScanService memindai file baru (status pending_scan) di background.
+// File yang terinfeksi dipindah ke prefix karantina dan tidak bisa diunduh.
+// File pending yang tertinggal (restart, scanner mati) diantrikan ulang berkala.
+type FileScanService struct {
+	files    repository.FileRepository
+	blobs    repository.IBlobRepository
+	store    storage.Storage
+	scanner  scanner.Scanner
+	interval time.Duration
+	queue    chan primitive.ObjectID
+}
+
+func NewFileScanService(files repository.FileRepository, blobs repository.IBlobRepository,
+	store storage.Storage, sc scanner.Scanner, interval time.Duration) *FileScanService {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &FileScanService{
+		files:    files,
+		blobs:    blobs,
+		store:    store,
+		scanner:  sc,
+		interval: interval,
+		queue:    make(chan primitive.ObjectID, 1000),
+	}
+}
+
+// Enqueue memasukkan file ke antrian pindai tanpa menunggu
+func (s *FileScanService) Enqueue(id primitive.ObjectID) {
+	select {
+	case s.queue <- id:
+	default:
+		log.Printf("Antrian pindai penuh, file %s dipindai pada putaran berikutnya", id.Hex())
+	}
+}
+
+// Start menjalankan worker pindai sampai ctx dibatalkan
+func (s *FileScanService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.requeuePending()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case id := <-s.queue:
+				s.scanByID(ctx, id)
+			case <-ticker.C:
+				s.requeuePending()
+			}
+		}
+	}()
+}
+
+func (s *FileScanService) requeuePending() {
+	files, err := s.files.FindByScanStatus(model.ScanPending)
+	if err != nil {
+		log.Println("Gagal mengambil file yang menunggu pindai:", err)
+		return
+	}
+	for _, f := range files {
+		s.Enqueue(f.ID)
+	}
+}
+
+func (s *FileScanService) scanByID(parent context.Context, id primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	f, err := s.files.FindByID(id.Hex())
+	if err != nil || f.ScanStatus != model.ScanPending {
+		// Sudah dihapus atau sudah dipindai lewat antrian lain
+		return
+	}
+	if err := s.scan(ctx, f); err != nil {
+		// Tetap pending sehingga dicoba lagi pada putaran berikutnya
+		log.Printf("Gagal memindai file %s: %v", id.Hex(), err)
+	}
+}
+
+func (s *FileScanService) scan(ctx context.Context, f *model.File) error {
+	r, _, err := s.store.Get(ctx, f.Key())
+	if err != nil {
+		return err
+	}
+	res, err := s.scanner.Scan(ctx, r)
+	r.Close()
+	if err != nil {
+		return err
+	}
+
+	if !res.Infected {
+		return s.files.SetScanResult(f.ID, model.ScanClean, "")
+	}
+	return s.quarantine(ctx, f, res.Signature)
+}
+
+// quarantine memindahkan isi file dan variant-nya ke prefix karantina, lalu
+// menandai semua file dengan isi yang sama sebagai infected
+func (s *FileScanService) quarantine(ctx context.Context, f *model.File, signature string) error {
+	key := f.Key()
+	target := func(k string) string {
+		if strings.HasPrefix(k, quarantinePrefix) {
+			return k
+		}
+		return quarantinePrefix + k
+	}
+
+	variants := make(map[string]model.FileVariant, len(f.Variants))
+	for name, v := range f.Variants {
+		if to := target(v.StorageKey); to != v.StorageKey {
+			if err := storage.Move(ctx, s.store, v.StorageKey, to); err != nil {
+				return err
+			}
+			v.StorageKey = to
+		}
+		variants[name] = v
+	}
+	newKey := target(key)
+	if newKey != key {
+		if err := storage.Move(ctx, s.store, key, newKey); err != nil {
+			return err
+		}
+	}
+
+	if f.StorageKey == "" {
+		// Data lama tanpa storage_key: cukup file ini yang ditandai
+		f.StorageKey, f.Variants = newKey, variants
+		f.ScanStatus, f.ScanResult = model.ScanInfected, signature
+		if err := s.files.Update(f); err != nil {
+			return err
+		}
+	} else if err := s.files.Quarantine(key, newKey, variants, signature); err != nil {
+		return err
+	}
+	if f.SHA256 != "" {
+		if err := s.blobs.Rekey(ctx, f.SHA256, newKey, variants); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("File %s (%s) terinfeksi %s, dipindah ke karantina", f.ID.Hex(), f.OriginalName, signature)
+	return nil
+}
+
+// ------------------- Handler Admin -------------------
+
+// Rescan memindai ulang satu file, misalnya setelah database signature diperbarui
+func (s *FileScanService) Rescan(c *fiber.Ctx) error {
+	f, err := s.files.FindByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "File tidak ditemukan"})
+	}
+	if err := s.files.SetScanResult(f.ID, model.ScanPending, ""); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengubah status pindai", "detail": err.Error()})
+	}
+	s.Enqueue(f.ID)
+
+	return c.Status(202).JSON(fiber.Map{
+		"id":          f.ID.Hex(),
+		"scan_status": model.ScanPending,
+	})
+}
+
+// MigrateScanStatusOnStartup menandai file lama tanpa scan_status sebagai pending_scan
+// saat aplikasi start, sebelum Start mengantrikan file pending. Tanpa ini file lama
+// dianggap bersih dan bisa diunduh tanpa pernah dipindai.
+func (s *FileScanService) MigrateScanStatusOnStartup() error {
+	migrated, err := s.files.MarkUnscannedPending()
+	if err != nil {
+		return err
+	}
+	if migrated > 0 {
+		log.Printf("Migrasi status pindai: %d file lama ditandai %s", migrated, model.ScanPending)
+	}
+	return nil
+}
+
+// MigrateScanStatus menandai file lama tanpa scan_status sebagai pending_scan
+// lalu mengantrikannya, sehingga file yang diupload sebelum ada pemindaian
+// ikut dipindai. Sampai dipindai bersih file tersebut tidak bisa diunduh.
+// Query: dry_run=true
+func (s *FileScanService) MigrateScanStatus(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", false)
+	total, err := s.files.CountUnscanned()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung file yang belum dipindai", "detail": err.Error()})
+	}
+
+	report := model.MigrationResult{DryRun: dryRun, Total: int(total), Failed: []model.MigrationFail{}}
+	if dryRun {
+		report.Migrated = int(total)
+		return c.JSON(report)
+	}
+
+	migrated, err := s.files.MarkUnscannedPending()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengubah status pindai", "detail": err.Error()})
+	}
+	report.Migrated = int(migrated)
+	// Sisa yang tidak muat di antrian diambil lagi oleh requeue berkala
+	s.requeuePending()
+
+	log.Printf("Migrasi status pindai: %d file lama ditandai %s", migrated, model.ScanPending)
+	return c.JSON(report)
+}
@@ -1,51 +1,337 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"log"
+	"net/url"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"praktikummongo/app/model"      // Sesuaikan nama modul
 	"praktikummongo/app/repository" // Sesuaikan nama modul
+	"praktikummongo/app/storage"
+	"praktikummongo/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type FileService interface {
 	UploadFile(c *fiber.Ctx) error
 	GetAllFiles(c *fiber.Ctx) error
+	GetMyFiles(c *fiber.Ctx) error
+	GetStorageUsage(c *fiber.Ctx) error
+	SetUserQuota(c *fiber.Ctx) error
 	GetFileByID(c *fiber.Ctx) error
 	DeleteFile(c *fiber.Ctx) error
+	UploadAlumniFile(c *fiber.Ctx) error
+	GetAlumniFiles(c *fiber.Ctx) error
+	UploadPekerjaanFile(c *fiber.Ctx) error
+	GetPekerjaanFiles(c *fiber.Ctx) error
+	DownloadFile(c *fiber.Ctx) error
+	CreateUploadSession(c *fiber.Ctx) error
+	GetUploadSession(c *fiber.Ctx) error
+	UploadChunk(c *fiber.Ctx) error
+	CompleteUpload(c *fiber.Ctx) error
+	AbortUpload(c *fiber.Ctx) error
+	StartUploadPurge(ctx context.Context)
+	MigrateImageVariants(c *fiber.Ctx) error
+	MigrateFileHashes(c *fiber.Ctx) error
+	GetDedupStats(c *fiber.Ctx) error
+	GetSignedURL(c *fiber.Ctx) error
+	SaveGenerated(meta *model.File, data []byte) (*model.FileResponse, error)
+}
+
+// FileConfig berisi pengaturan upload yang dibaca dari environment
+type FileConfig struct {
+	SizeLimits map[string]int64 // batas per kategori, menimpa model.BatasUkuranFile
+	SessionTTL time.Duration    // masa berlaku sesi upload bertahap sejak potongan terakhir
+	RoleQuotas map[string]int64 // kuota penyimpanan per role; role yang tidak disebut tidak dibatasi
 }
 
 type fileService struct {
-	repo       repository.FileRepository
-	uploadPath string
+	repo          repository.FileRepository
+	uploadRepo    repository.IUploadSessionRepository
+	blobRepo      repository.IBlobRepository
+	userRepo      repository.IUserRepository
+	alumniRepo    repository.IAlumniRepository
+	pekerjaanRepo repository.IPekerjaanRepository
+	store         storage.Storage
+	scans         *FileScanService
+	sizeLimits    map[string]int64
+	sessionTTL    time.Duration
+	roleQuotas    map[string]int64
 }
 
-func NewFileService(repo repository.FileRepository, uploadPath string) FileService {
+func NewFileService(repo repository.FileRepository, uploadRepo repository.IUploadSessionRepository,
+	blobRepo repository.IBlobRepository, userRepo repository.IUserRepository, alumniRepo repository.IAlumniRepository, pekerjaanRepo repository.IPekerjaanRepository,
+	store storage.Storage, scans *FileScanService, cfg FileConfig) FileService {
+	limits := GabungBatasUkuran(cfg.SizeLimits)
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = 24 * time.Hour
+	}
 	return &fileService{
-		repo:       repo,
-		uploadPath: uploadPath,
+		repo:          repo,
+		uploadRepo:    uploadRepo,
+		blobRepo:      blobRepo,
+		userRepo:      userRepo,
+		alumniRepo:    alumniRepo,
+		pekerjaanRepo: pekerjaanRepo,
+		store:         store,
+		scans:         scans,
+		sizeLimits:    limits,
+		sessionTTL:    cfg.SessionTTL,
+		roleQuotas:    cfg.RoleQuotas,
+	}
+}
+
+// GabungBatasUkuran menimpa batas default model.BatasUkuranFile dengan batas dari konfigurasi
+func GabungBatasUkuran(cfg map[string]int64) map[string]int64 {
+	limits := make(map[string]int64, len(model.BatasUkuranFile))
+	for k, v := range model.BatasUkuranFile {
+		limits[k] = v
 	}
+	for k, v := range cfg {
+		limits[k] = v
+	}
+	return limits
+}
+
+// BodyLimit menghitung batas body request dari batas kategori terbesar ditambah
+// ruang untuk header multipart, minimal cukup untuk satu potongan upload bertahap
+func BodyLimit(limits map[string]int64) int {
+	max := int64(maxChunkSize)
+	for _, n := range limits {
+		if n > max {
+			max = n
+		}
+	}
+	return int(max + 1<<20)
+}
+
+// batasUkuran mengembalikan ukuran maksimum file untuk kategori
+func (s *fileService) batasUkuran(category string) int64 {
+	if n, ok := s.sizeLimits[category]; ok {
+		return n
+	}
+	return s.sizeLimits["*"]
+}
+
+func (s *fileService) errUkuran(category string) error {
+	limit := s.batasUkuran(category)
+	return &fileError{fiber.StatusRequestEntityTooLarge, "File size exceeds " + utils.FormatUkuran(limit),
+		fiber.Map{"category": category, "max_size": limit}}
 }
 
 // helper function untuk mapping
 func (s *fileService) toFileResponse(file *model.File) *model.FileResponse {
-	return &model.FileResponse{
+	resp := &model.FileResponse{
 		ID:           file.ID.Hex(),
 		FileName:     file.FileName,
-		OriginalName: file.OriginalName,
-		FilePath:     file.FilePath,
+		OriginalName: utils.SanitizeFileName(file.OriginalName),
 		FileSize:     file.FileSize,
 		FileType:     file.FileType,
+		Category:     file.Category,
+		EntityType:   file.EntityType,
+		ScanStatus:   file.ScanStatus,
+		DownloadURL:  "/api/files/" + file.ID.Hex() + "/download",
 		UploadedAt:   file.UploadedAt,
 	}
+	if file.EntityID != nil {
+		resp.EntityID = file.EntityID.Hex()
+	}
+	if file.UploadedBy != nil {
+		resp.UploadedBy = file.UploadedBy.Hex()
+	}
+	if len(file.Variants) > 0 {
+		resp.Variants = make(map[string]string, len(file.Variants))
+		for name := range file.Variants {
+			resp.Variants[name] = resp.DownloadURL + "?variant=" + name
+		}
+	}
+	return resp
+}
+
+// fileError adalah error akses / validasi file beserta status HTTP-nya.
+// detail berisi field tambahan untuk response (boleh nil).
+type fileError struct {
+	status  int
+	message string
+	detail  fiber.Map
+}
+
+func (e *fileError) Error() string { return e.message }
+
+func (s *fileService) sendError(c *fiber.Ctx, err error) error {
+	if fe, ok := err.(*fileError); ok {
+		resp := fiber.Map{"success": false, "message": fe.message}
+		for k, v := range fe.detail {
+			resp[k] = v
+		}
+		return c.Status(fe.status).JSON(resp)
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"message": "Failed to check file owner",
+		"error":   err.Error(),
+	})
+}
+
+// kuota mengembalikan kuota penyimpanan user yang login. Kuota khusus user
+// didahulukan dari kuota role; ok false berarti tidak dibatasi.
+func (s *fileService) kuota(ctx context.Context, c *fiber.Ctx) (quota int64, ok bool, err error) {
+	if id := uploaderID(c); id != nil {
+		user, err := s.userRepo.GetUserByID(ctx, *id)
+		if err != nil {
+			return 0, false, err
+		}
+		if user != nil && user.StorageQuota != nil {
+			return *user.StorageQuota, true, nil
+		}
+	}
+	role, _ := c.Locals("role").(string)
+	quota, ok = s.roleQuotas[role]
+	return quota, ok, nil
+}
+
+// cekKuota menolak upload sebesar size jika melebihi sisa kuota user
+func (s *fileService) cekKuota(ctx context.Context, c *fiber.Ctx, size int64) error {
+	quota, ok, err := s.kuota(ctx, c)
+	if err != nil || !ok {
+		return err
+	}
+	id := uploaderID(c)
+	if id == nil {
+		return &fileError{fiber.StatusUnauthorized, "Invalid user ID", nil}
+	}
+	usage, err := s.repo.Usage(*id)
+	if err != nil {
+		return err
+	}
+	if usage.Bytes+size > quota {
+		return &fileError{fiber.StatusRequestEntityTooLarge, "Storage quota exceeded", fiber.Map{
+			"quota":     quota,
+			"used":      usage.Bytes,
+			"remaining": max(quota-usage.Bytes, 0),
+		}}
+	}
+	return nil
 }
 
+// checkEntityAccess memastikan entitas ada dan user boleh mengakses file-nya:
+// admin boleh semua, user hanya data alumni miliknya sendiri (alumni_id == user_id)
+func (s *fileService) checkEntityAccess(c *fiber.Ctx, entityType, entityID string) (*primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(entityID)
+	if err != nil {
+		return nil, &fileError{fiber.StatusBadRequest, "Invalid entity ID", nil}
+	}
+
+	var ownerID primitive.ObjectID
+	switch entityType {
+	case model.EntityAlumni:
+		alumni, err := s.alumniRepo.GetByID(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		if alumni == nil {
+			return nil, &fileError{fiber.StatusNotFound, "Alumni not found", nil}
+		}
+		ownerID = alumni.ID
+	case model.EntityPekerjaan:
+		owner, deleted, err := s.pekerjaanRepo.GetOwnerAndDeleteStatus(ctx, entityID)
+		if err != nil || owner == nil || (deleted != nil && *deleted) {
+			return nil, &fileError{fiber.StatusNotFound, "Pekerjaan not found", nil}
+		}
+		ownerID = *owner
+	default:
+		return nil, &fileError{fiber.StatusBadRequest, "entity_type must be alumni or pekerjaan", nil}
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	if role != "admin" && ownerID.Hex() != userID {
+		return nil, &fileError{fiber.StatusForbidden, "You are not allowed to access files of this " + entityType, nil}
+	}
+	return &objID, nil
+}
+
+// checkFileAccess: admin dan pengupload selalu boleh; file yang tertaut ke
+// entitas mengikuti aturan checkEntityAccess; file lepas lainnya hanya admin
+func (s *fileService) checkFileAccess(c *fiber.Ctx, file *model.File) error {
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	if role == "admin" || (file.UploadedBy != nil && file.UploadedBy.Hex() == userID) {
+		return nil
+	}
+	if file.EntityType != "" && file.EntityID != nil {
+		_, err := s.checkEntityAccess(c, file.EntityType, file.EntityID.Hex())
+		return err
+	}
+	return &fileError{fiber.StatusForbidden, "You are not allowed to access this file", nil}
+}
+
+// validCategory memeriksa kategori dokumen untuk entitas; kosong berarti "lainnya"
+func validCategory(entityType, category string) (string, bool) {
+	if category == "" {
+		return "lainnya", true
+	}
+	for _, k := range model.KategoriFile[entityType] {
+		if k == category {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// uploaderID mengambil user_id dari JWT sebagai ObjectID
+func uploaderID(c *fiber.Ctx) *primitive.ObjectID {
+	userID, _ := c.Locals("user_id").(string)
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil
+	}
+	return &objID
+}
+
+// UploadFile menerima file lepas, atau file untuk entitas lewat field form
+// entity_type dan entity_id. Field category opsional.
 func (s *fileService) UploadFile(c *fiber.Ctx) error {
+	entityType := c.FormValue("entity_type")
+	entityID := c.FormValue("entity_id")
+	if entityType == "" && entityID == "" {
+		return s.storeUpload(c, "", nil)
+	}
+
+	objID, err := s.checkEntityAccess(c, entityType, entityID)
+	if err != nil {
+		return s.sendError(c, err)
+	}
+	return s.storeUpload(c, entityType, objID)
+}
+
+// storeUpload memvalidasi dan menyimpan file dari field form 'file'
+func (s *fileService) storeUpload(c *fiber.Ctx, entityType string, entityID *primitive.ObjectID) error {
+	category, ok := validCategory(entityType, c.FormValue("category"))
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid category",
+			"allowed": model.KategoriFile[entityType],
+		})
+	}
+
 	// Get file from form
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
@@ -56,117 +342,388 @@ func (s *fileService) UploadFile(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validasi ukuran file (max 10MB)
-	if fileHeader.Size > 10*1024*1024 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Validasi ukuran file sesuai batas kategori dan kuota user
+	if fileHeader.Size == 0 {
+		return s.sendError(c, &fileError{fiber.StatusBadRequest, "File is empty", nil})
+	}
+	if fileHeader.Size > s.batasUkuran(category) {
+		return s.sendError(c, s.errUkuran(category))
+	}
+	if err := s.cekKuota(ctx, c, fileHeader.Size); err != nil {
+		return s.sendError(c, err)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "File size exceeds 10MB",
+			"message": "Failed to open file",
+			"error":   err.Error(),
 		})
 	}
+	defer file.Close()
 
-	// Validasi tipe file
-	allowedTypes := map[string]bool{
-		"image/jpeg":    true,
-		"image/png":     true,
-		"image/jpg":     true,
-		"application/pdf": true,
+	fileModel, err := s.simpanBlob(ctx, fileHeader.Filename, fileHeader.Header.Get("Content-Type"), fileHeader.Size, file)
+	if err != nil {
+		return s.sendError(c, err)
 	}
-	contentType := fileHeader.Header.Get("Content-Type")
-	if !allowedTypes[contentType] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+
+	// Simpan metadata ke database
+	fileModel.Category = category
+	fileModel.EntityType = entityType
+	fileModel.EntityID = entityID
+	fileModel.UploadedBy = uploaderID(c)
+	fileModel.ScanStatus = model.ScanPending
+
+	if err := s.repo.Create(fileModel); err != nil {
+		// Hapus file jika gagal simpan ke database
+		s.deleteStored(ctx, fileModel)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "File type not allowed",
+			"message": "Failed to save file metadata",
+			"error":   err.Error(),
 		})
 	}
+	s.scans.Enqueue(fileModel.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "File uploaded successfully",
+		"data":    s.toFileResponse(fileModel),
+	})
+}
+
+// simpanBlob memvalidasi tipe dari isi file lalu menyimpannya ke storage.
+// Gambar dibersihkan dari metadata dan dibuatkan variant. Isi yang SHA-256-nya
+// sudah pernah disimpan tidak disimpan ulang, cukup menambah referensi blob.
+//
+// Key storage sengaja uuid acak, bukan SHA-256: isi di-stream langsung ke storage
+// sehingga hash baru diketahui setelah Put selesai, dan key blob bisa berubah
+// (dipindah ke karantina). Deduplikasi dilakukan lewat dokumen blob ber-_id SHA-256;
+// jika hash sudah ada, salinan dengan key baru langsung dihapus.
+// Mengembalikan model.File tanpa kategori / entitas; metadata belum disimpan ke database.
+func (s *fileService) simpanBlob(ctx context.Context, name, contentType string, size int64, r io.Reader) (*model.File, error) {
+	// Validasi tipe file dari isinya, bukan dari Content-Type / ekstensi klien
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err == io.EOF {
+		return nil, &fileError{fiber.StatusBadRequest, "File is empty", nil}
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, &fileError{fiber.StatusInternalServerError, "Failed to read file", fiber.Map{"error": err.Error()}}
+	}
+	head = head[:n]
+	fileType := utils.DetectFileType(head)
+	if fileType == nil {
+		return nil, &fileError{fiber.StatusBadRequest, "File type not allowed", nil}
+	}
+	if !fileType.MatchesContentType(contentType) || !fileType.MatchesName(name) {
+		return nil, &fileError{fiber.StatusBadRequest, "File content does not match its declared type or extension",
+			fiber.Map{"detected": fileType.MIME}}
+	}
+	// Hash dihitung dari isi asli yang diupload, sebelum metadata gambar dibuang
+	h := sha256.New()
+	tee := io.TeeReader(io.MultiReader(bytes.NewReader(head), r), h)
+	var body io.Reader = tee
+
+	// Generate unique filename, ekstensi mengikuti tipe yang terdeteksi
+	newFileName := uuid.New().String() + fileType.Ext
+
+	// Gambar: metadata EXIF dibuang dan variant thumb / web dibuat
+	var variants map[string]model.FileVariant
+	if isImage(fileType.MIME) {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, &fileError{fiber.StatusInternalServerError, "Failed to read file", fiber.Map{"error": err.Error()}}
+		}
+		clean, v, err := s.processImage(ctx, newFileName, fileType.MIME, data)
+		if err != nil {
+			return nil, &fileError{fiber.StatusBadRequest, "Invalid image", fiber.Map{"error": err.Error()}}
+		}
+		body, size, variants = bytes.NewReader(clean), int64(len(clean)), v
+	}
+
+	// Simpan isi file ke storage
+	if err := s.store.Put(ctx, newFileName, body, size, fileType.MIME); err != nil {
+		s.deleteVariants(ctx, variants)
+		return nil, &fileError{fiber.StatusInternalServerError, "Failed to save file", fiber.Map{"error": err.Error()}}
+	}
+	io.Copy(io.Discard, tee)
+
+	blob, err := s.blobRepo.Acquire(ctx, &model.Blob{
+		ID:         hex.EncodeToString(h.Sum(nil)),
+		StorageKey: newFileName,
+		Size:       size,
+		FileType:   fileType.MIME,
+		Variants:   variants,
+	})
+	if err != nil {
+		s.deleteKeys(ctx, newFileName, variants)
+		return nil, &fileError{fiber.StatusInternalServerError, "Failed to save file", fiber.Map{"error": err.Error()}}
+	}
+	if blob.StorageKey != newFileName {
+		// Duplikat: pakai blob yang sudah ada dan buang salinan barusan
+		s.deleteKeys(ctx, newFileName, variants)
+	}
+
+	return &model.File{
+		FileName:     blob.StorageKey,
+		OriginalName: utils.SanitizeFileName(name),
+		StorageKey:   blob.StorageKey,
+		FileSize:     blob.Size,
+		FileType:     blob.FileType,
+		SHA256:       blob.ID,
+		Variants:     blob.Variants,
+	}, nil
+}
+
+// SaveGenerated menyimpan file yang dibuat server (misalnya laporan PDF)
+// ke storage dan mencatat metadatanya seperti file upload biasa.
+// meta berisi OriginalName, FileType, dan tautan entitas / kategori.
+func (s *fileService) SaveGenerated(meta *model.File, data []byte) (*model.FileResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	blob, err := s.simpanBlob(ctx, meta.OriginalName, meta.FileType, int64(len(data)), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	meta.OriginalName = blob.OriginalName
+	meta.FileName = blob.FileName
+	meta.StorageKey = blob.StorageKey
+	meta.FileSize = blob.FileSize
+	meta.FileType = blob.FileType
+	meta.SHA256 = blob.SHA256
+	meta.Variants = blob.Variants
+	// Dibuat oleh server sendiri, tidak perlu dipindai
+	meta.ScanStatus = model.ScanClean
+
+	if err := s.repo.Create(meta); err != nil {
+		s.deleteStored(ctx, meta)
+		return nil, err
+	}
+	return s.toFileResponse(meta), nil
+}
 
-	// Generate unique filename
-	ext := filepath.Ext(fileHeader.Filename)
-	newFileName := uuid.New().String() + ext
-	filePath := filepath.Join(s.uploadPath, newFileName)
+// parseFileFilter membaca query listing file: page, limit, type, category,
+// entity_type, entity_id, uploaded_by, uploaded_after, uploaded_before, scan_status
+func parseFileFilter(c *fiber.Ctx) (model.FileFilter, error) {
+	f := model.FileFilter{
+		Page:       c.QueryInt("page", 1),
+		Limit:      c.QueryInt("limit", 10),
+		FileType:   c.Query("type"),
+		Category:   c.Query("category"),
+		EntityType: c.Query("entity_type"),
+		ScanStatus: c.Query("scan_status"),
+	}
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 || f.Limit > 100 {
+		f.Limit = 10
+	}
+	for param, dst := range map[string]**primitive.ObjectID{
+		"entity_id":   &f.EntityID,
+		"uploaded_by": &f.UploadedBy,
+	} {
+		if v := c.Query(param); v != "" {
+			objID, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				return f, fmt.Errorf("%s is not a valid ID", param)
+			}
+			*dst = &objID
+		}
+	}
+	for param, dst := range map[string]**time.Time{
+		"uploaded_after":  &f.UploadedAfter,
+		"uploaded_before": &f.UploadedBefore,
+	} {
+		if v := c.Query(param); v != "" {
+			t, err := utils.ParseTanggal(v)
+			if err != nil {
+				return f, fmt.Errorf("%s is not a valid date: %w", param, err)
+			}
+			*dst = &t
+		}
+	}
+	return f, nil
+}
 
-	// Buat folder jika belum ada
-	if err := os.MkdirAll(s.uploadPath, os.ModePerm); err != nil {
+// listFiles menjalankan filter dan mengirim satu halaman hasil
+func (s *fileService) listFiles(c *fiber.Ctx, f model.FileFilter, extra fiber.Map) error {
+	files, total, err := s.repo.Find(f)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to create upload directory",
+			"message": "Failed to get files",
 			"error":   err.Error(),
 		})
 	}
 
-	// Simpan file
-	file, err := fileHeader.Open()
+	responses := make([]*model.FileResponse, 0, len(files))
+	for i := range files {
+		responses = append(responses, s.toFileResponse(&files[i]))
+	}
+
+	resp := fiber.Map{
+		"success":     true,
+		"message":     "Files retrieved successfully",
+		"data":        responses,
+		"page":        f.Page,
+		"limit":       f.Limit,
+		"total":       total,
+		"total_pages": (total + int64(f.Limit) - 1) / int64(f.Limit),
+	}
+	for k, v := range extra {
+		resp[k] = v
+	}
+	return c.JSON(resp)
+}
+
+// GetAllFiles: admin melihat semua file (bisa difilter uploaded_by),
+// user lain hanya file yang diuploadnya sendiri
+func (s *fileService) GetAllFiles(c *fiber.Ctx) error {
+	f, err := parseFileFilter(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to open file",
-			"error":   err.Error(),
+			"message": err.Error(),
 		})
 	}
-	defer file.Close()
+	if role, _ := c.Locals("role").(string); role != "admin" {
+		f.UploadedBy = uploaderID(c)
+		if f.UploadedBy == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid user ID",
+			})
+		}
+	}
+	return s.listFiles(c, f, nil)
+}
 
-	out, err := os.Create(filePath)
+// GetMyFiles menampilkan file milik user yang login beserta pemakaian kuotanya
+func (s *fileService) GetMyFiles(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	f, err := parseFileFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+	f.UploadedBy = uploaderID(c)
+	if f.UploadedBy == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid user ID",
+		})
+	}
+
+	usage, err := s.repo.Usage(*f.UploadedBy)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to save file",
+			"message": "Failed to get storage usage",
 			"error":   err.Error(),
 		})
 	}
-	defer out.Close()
-
-	if _, err := io.Copy(out, file); err != nil {
+	quota, limited, err := s.kuota(ctx, c)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to write file",
+			"message": "Failed to get storage quota",
 			"error":   err.Error(),
 		})
 	}
-
-	// Simpan metadata ke database
-	fileModel := &model.File{
-		FileName:     newFileName,
-		OriginalName: fileHeader.Filename,
-		FilePath:     filePath,
-		FileSize:     fileHeader.Size,
-		FileType:     contentType,
+	info := fiber.Map{"files": usage.Files, "used": usage.Bytes, "quota": nil, "remaining": nil}
+	if limited {
+		info["quota"] = quota
+		info["remaining"] = max(quota-usage.Bytes, 0)
 	}
 
-	if err := s.repo.Create(fileModel); err != nil {
-		// Hapus file jika gagal simpan ke database
-		os.Remove(filePath)
+	return s.listFiles(c, f, fiber.Map{"usage": info})
+}
+
+// GetStorageUsage menampilkan pemakaian penyimpanan per user, terbesar dulu
+func (s *fileService) GetStorageUsage(c *fiber.Ctx) error {
+	usage, err := s.repo.UsageByUser()
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to save file metadata",
+			"message": "Failed to get storage usage",
 			"error":   err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"message": "File uploaded successfully",
-		"data":    s.toFileResponse(fileModel),
+	return c.JSON(fiber.Map{
+		"success":     true,
+		"data":        usage,
+		"role_quotas": s.roleQuotas,
 	})
 }
 
-func (s *fileService) GetAllFiles(c *fiber.Ctx) error {
-	files, err := s.repo.FindAll()
+// SetUserQuota mengatur kuota khusus user. Body: {"quota": "500MB"};
+// {"quota": null} menghapusnya sehingga kembali ke kuota role.
+func (s *fileService) SetUserQuota(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"message": "Failed to get files",
+			"message": "Invalid user ID",
+		})
+	}
+
+	var req struct {
+		Quota *string `json:"quota"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
 			"error":   err.Error(),
 		})
 	}
 
-	var responses []*model.FileResponse
-	for _, file := range files {
-		responses = append(responses, s.toFileResponse(&file))
+	var quota *int64
+	if req.Quota != nil {
+		n, err := utils.ParseUkuran(*req.Quota)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": err.Error(),
+			})
+		}
+		quota = &n
+	}
+
+	found, err := s.userRepo.SetStorageQuota(ctx, userID, quota)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update storage quota",
+			"error":   err.Error(),
+		})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": "User not found",
+		})
 	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"message": "Files retrieved successfully",
-		"data":    responses,
+		"message": "Storage quota updated",
+		"data":    fiber.Map{"user_id": userID.Hex(), "quota": quota},
 	})
 }
 
@@ -181,6 +738,10 @@ func (s *fileService) GetFileByID(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := s.checkFileAccess(c, file); err != nil {
+		return s.sendError(c, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "File retrieved successfully",
@@ -188,6 +749,169 @@ func (s *fileService) GetFileByID(c *fiber.Ctx) error {
 	})
 }
 
+// ------------------- Download -------------------
+
+// Batas umur URL bertanda tangan
+const (
+	defaultSignedURLTTL = 5 * time.Minute
+	maxSignedURLTTL     = time.Hour
+)
+
+// contentDisposition membentuk header dengan nama file ASCII dan versi UTF-8 (RFC 6266)
+func contentDisposition(disposition, name string) string {
+	ascii := make([]rune, 0, len(name))
+	for _, r := range name {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			r = '_'
+		}
+		ascii = append(ascii, r)
+	}
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, string(ascii), url.PathEscape(name))
+}
+
+// DownloadFile - GET /files/:id/download. Akses lewat JWT atau URL bertanda tangan.
+// Query inline=true untuk ditampilkan di browser; header Range didukung.
+func (s *fileService) DownloadFile(c *fiber.Ctx) error {
+	file, err := s.repo.FindByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": "File not found",
+		})
+	}
+
+	// URL bertanda tangan sudah diverifikasi middleware untuk file ini
+	if signed, _ := c.Locals("signed_url").(bool); !signed {
+		if err := s.checkFileAccess(c, file); err != nil {
+			return s.sendError(c, err)
+		}
+	}
+
+	// File baru baru boleh diunduh setelah dipindai bersih
+	if !file.Downloadable() {
+		if file.ScanStatus == model.ScanInfected {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": "File is quarantined because malware was detected",
+			})
+		}
+		return c.Status(fiber.StatusLocked).JSON(fiber.Map{
+			"success":     false,
+			"message":     "File is waiting for malware scan",
+			"scan_status": file.ScanStatus,
+		})
+	}
+
+	key, contentType := file.Key(), file.FileType
+	if name := c.Query("variant"); name != "" {
+		v, ok := file.Variants[name]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": "Variant not found",
+			})
+		}
+		key, contentType = v.StorageKey, v.FileType
+	}
+
+	// Context tidak dibatalkan di sini karena isi file dibaca setelah handler selesai
+	f, obj, err := s.store.Get(context.Background(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": "File content not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read file",
+			"error":   err.Error(),
+		})
+	}
+
+	disposition := "attachment"
+	if c.QueryBool("inline", false) {
+		disposition = "inline"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, contentDisposition(disposition, utils.SanitizeFileName(file.OriginalName)))
+	c.Set("X-Content-Type-Options", "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=300")
+	return sendContent(c, f, obj.Size, file.UploadedAt)
+}
+
+// sendContent mengirim isi file dengan dukungan Range (satu rentang).
+// f ditutup setelah response selesai dikirim.
+func sendContent(c *fiber.Ctx, f io.ReadSeekCloser, size int64, modTime time.Time) error {
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderLastModified, modTime.UTC().Format(time.RFC1123))
+
+	rangeHeader := c.Get(fiber.HeaderRange)
+	// If-Range dengan tanggal berbeda berarti file berubah: kirim utuh
+	if ifRange := c.Get(fiber.HeaderIfRange); ifRange != "" && ifRange != modTime.UTC().Format(time.RFC1123) {
+		rangeHeader = ""
+	}
+
+	start, length, partial, err := utils.ParseRange(rangeHeader, size)
+	if errors.Is(err, utils.ErrRangeTidakValid) {
+		f.Close()
+		c.Set(fiber.HeaderContentRange, "bytes */"+strconv.FormatInt(size, 10))
+		return c.SendStatus(fiber.StatusRequestedRangeNotSatisfiable)
+	}
+
+	if partial {
+		if _, err := f.Seek(start, io.SeekStart); err != nil {
+			f.Close()
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		c.Status(fiber.StatusPartialContent)
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, size))
+	}
+
+	c.Context().SetBodyStream(struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, length), f}, int(length))
+	return nil
+}
+
+// GetSignedURL - GET /files/:id/signed-url?ttl=300 membuat URL download berumur pendek
+// yang bisa dipakai tanpa header Authorization (misalnya di tag <img>)
+func (s *fileService) GetSignedURL(c *fiber.Ctx) error {
+	file, err := s.repo.FindByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": "File not found",
+		})
+	}
+	if err := s.checkFileAccess(c, file); err != nil {
+		return s.sendError(c, err)
+	}
+
+	ttl := defaultSignedURLTTL
+	if v := c.QueryInt("ttl", 0); v > 0 {
+		ttl = time.Duration(v) * time.Second
+	}
+	if ttl > maxSignedURLTTL {
+		ttl = maxSignedURLTTL
+	}
+
+	id := file.ID.Hex()
+	expires, sig := utils.SignFileURL(id, ttl)
+	signedURL := fmt.Sprintf("/api/files/%s/download?inline=true&expires=%d&sig=%s", id, expires, sig)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Signed URL created successfully",
+		"data": fiber.Map{
+			"url":        signedURL,
+			"expires_at": time.Unix(expires, 0),
+		},
+	})
+}
+
 func (s *fileService) DeleteFile(c *fiber.Ctx) error {
 	id := c.Params("id")
 	file, err := s.repo.FindByID(id)
@@ -199,12 +923,18 @@ func (s *fileService) DeleteFile(c *fiber.Ctx) error {
 		})
 	}
 
-	// Hapus file dari storage
-	if err := os.Remove(file.FilePath); err != nil {
-		fmt.Println("Warning: Failed to delete file from storage:", err)
+	// Hanya admin atau pengupload yang boleh menghapus
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	if role != "admin" && (file.UploadedBy == nil || file.UploadedBy.Hex() != userID) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": "You are not allowed to delete this file",
+		})
 	}
 
-	// Hapus dari database
+	// Hapus dari database lebih dulu agar referensi blob tidak berkurang
+	// untuk metadata yang ternyata gagal dihapus
 	if err := s.repo.Delete(id); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -213,8 +943,323 @@ func (s *fileService) DeleteFile(c *fiber.Ctx) error {
 		})
 	}
 
+	// Hapus file dari storage (blob hanya dihapus jika referensi terakhir)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s.deleteStored(ctx, file)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "File deleted successfully",
 	})
-}
\ No newline at end of file
+}
+
+// ------------------- File per Entitas -------------------
+
+func (s *fileService) uploadEntityFile(c *fiber.Ctx, entityType string) error {
+	objID, err := s.checkEntityAccess(c, entityType, c.Params("id"))
+	if err != nil {
+		return s.sendError(c, err)
+	}
+	return s.storeUpload(c, entityType, objID)
+}
+
+func (s *fileService) getEntityFiles(c *fiber.Ctx, entityType string) error {
+	objID, err := s.checkEntityAccess(c, entityType, c.Params("id"))
+	if err != nil {
+		return s.sendError(c, err)
+	}
+
+	files, err := s.repo.FindByEntity(entityType, *objID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to get files",
+			"error":   err.Error(),
+		})
+	}
+
+	responses := make([]*model.FileResponse, 0, len(files))
+	for i := range files {
+		responses = append(responses, s.toFileResponse(&files[i]))
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Files retrieved successfully",
+		"data":    responses,
+	})
+}
+
+// UploadAlumniFile - POST /alumni/:id/files (CV, ijazah, transkrip, ...)
+func (s *fileService) UploadAlumniFile(c *fiber.Ctx) error {
+	return s.uploadEntityFile(c, model.EntityAlumni)
+}
+
+// GetAlumniFiles - GET /alumni/:id/files
+func (s *fileService) GetAlumniFiles(c *fiber.Ctx) error {
+	return s.getEntityFiles(c, model.EntityAlumni)
+}
+
+// UploadPekerjaanFile - POST /pekerjaan/:id/files (surat keterangan kerja, kontrak, ...)
+func (s *fileService) UploadPekerjaanFile(c *fiber.Ctx) error {
+	return s.uploadEntityFile(c, model.EntityPekerjaan)
+}
+
+// GetPekerjaanFiles - GET /pekerjaan/:id/files
+func (s *fileService) GetPekerjaanFiles(c *fiber.Ctx) error {
+	return s.getEntityFiles(c, model.EntityPekerjaan)
+}
+
+// ------------------- Variant Gambar -------------------
+
+func isImage(mime string) bool {
+	return mime == "image/jpeg" || mime == "image/png"
+}
+
+// processImage membuang metadata gambar asli dan menyimpan variant thumb / web.
+// Mengembalikan isi gambar asli yang sudah bersih.
+func (s *fileService) processImage(ctx context.Context, key, mime string, data []byte) ([]byte, map[string]model.FileVariant, error) {
+	var clean []byte
+	var err error
+	if mime == "image/png" {
+		clean, err = utils.StripPNGMetadata(data)
+	} else {
+		clean, err = utils.StripJPEGMetadata(data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := strings.TrimSuffix(key, filepath.Ext(key))
+	variants := map[string]model.FileVariant{}
+	for name, size := range model.UkuranVariant {
+		img, err := utils.ResizeImage(clean, mime, size[0], size[1])
+		if err != nil {
+			s.deleteVariants(ctx, variants)
+			return nil, nil, err
+		}
+		v := model.FileVariant{
+			StorageKey: base + "_" + name + filepath.Ext(key),
+			FileSize:   int64(len(img.Data)),
+			FileType:   mime,
+			Width:      img.Width,
+			Height:     img.Height,
+		}
+		if err := s.store.Put(ctx, v.StorageKey, bytes.NewReader(img.Data), v.FileSize, mime); err != nil {
+			s.deleteVariants(ctx, variants)
+			return nil, nil, err
+		}
+		variants[name] = v
+	}
+	return clean, variants, nil
+}
+
+func (s *fileService) deleteVariants(ctx context.Context, variants map[string]model.FileVariant) {
+	for _, v := range variants {
+		if err := s.store.Delete(ctx, v.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Println("Warning: Failed to delete file variant from storage:", err)
+		}
+	}
+}
+
+// deleteKeys menghapus isi file beserta variant-nya dari storage
+func (s *fileService) deleteKeys(ctx context.Context, key string, variants map[string]model.FileVariant) {
+	if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Println("Warning: Failed to delete file from storage:", err)
+	}
+	s.deleteVariants(ctx, variants)
+}
+
+// deleteStored melepas referensi file ke blob-nya; isi di storage baru dihapus
+// saat referensi terakhir hilang. File lama tanpa sha256 langsung dihapus.
+func (s *fileService) deleteStored(ctx context.Context, file *model.File) {
+	if file.SHA256 == "" {
+		s.deleteKeys(ctx, file.Key(), file.Variants)
+		return
+	}
+
+	blob, err := s.blobRepo.Release(ctx, file.SHA256)
+	if err != nil {
+		log.Println("Warning: Failed to release file blob:", err)
+		return
+	}
+	if blob != nil {
+		s.deleteKeys(ctx, blob.StorageKey, blob.Variants)
+	}
+}
+
+// MigrateImageVariants membuat variant dan membuang EXIF untuk gambar yang diupload
+// sebelum fitur variant ada. Query: dry_run=true
+func (s *fileService) MigrateImageVariants(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	dryRun := c.QueryBool("dry_run", false)
+	files, err := s.repo.FindImagesWithoutVariants()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to get files",
+			"error":   err.Error(),
+		})
+	}
+
+	report := model.MigrationResult{DryRun: dryRun, Total: len(files), Failed: []model.MigrationFail{}}
+	fail := func(f *model.File, err error) {
+		report.Failed = append(report.Failed, migrationFail(f.ID, "storage_key", f.Key(), err))
+	}
+
+	for i := range files {
+		f := &files[i]
+		if dryRun {
+			report.Migrated++
+			continue
+		}
+
+		r, _, err := s.store.Get(ctx, f.Key())
+		if err != nil {
+			fail(f, err)
+			continue
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			fail(f, err)
+			continue
+		}
+
+		// Deteksi ulang karena data lama menyimpan Content-Type dari klien
+		ft := utils.DetectFileType(data)
+		if ft == nil || !isImage(ft.MIME) {
+			fail(f, errors.New("isi file bukan gambar JPEG / PNG"))
+			continue
+		}
+		clean, variants, err := s.processImage(ctx, f.Key(), ft.MIME, data)
+		if err != nil {
+			fail(f, err)
+			continue
+		}
+		if err := s.store.Put(ctx, f.Key(), bytes.NewReader(clean), int64(len(clean)), ft.MIME); err != nil {
+			s.deleteVariants(ctx, variants)
+			fail(f, err)
+			continue
+		}
+
+		f.StorageKey = f.Key()
+		f.FileSize = int64(len(clean))
+		f.FileType = ft.MIME
+		f.Variants = variants
+		if err := s.repo.Update(f); err != nil {
+			s.deleteVariants(ctx, variants)
+			fail(f, err)
+			continue
+		}
+		report.Migrated++
+	}
+
+	if !dryRun {
+		log.Printf("Migrasi variant gambar: %d dari %d file dimigrasi, %d gagal",
+			report.Migrated, report.Total, len(report.Failed))
+	}
+	return c.JSON(report)
+}
+
+// ------------------- Deduplikasi -------------------
+
+// MigrateFileHashes menghitung SHA-256 file lama dan mendaftarkannya sebagai blob.
+// File lama yang isinya sama digabung ke satu blob dan salinannya dihapus.
+// Query: dry_run=true
+func (s *fileService) MigrateFileHashes(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	dryRun := c.QueryBool("dry_run", false)
+	files, err := s.repo.FindWithoutHash()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to get files",
+			"error":   err.Error(),
+		})
+	}
+
+	report := model.MigrationResult{DryRun: dryRun, Total: len(files), Failed: []model.MigrationFail{}}
+	fail := func(f *model.File, err error) {
+		report.Failed = append(report.Failed, migrationFail(f.ID, "storage_key", f.Key(), err))
+	}
+
+	for i := range files {
+		f := &files[i]
+		if dryRun {
+			report.Migrated++
+			continue
+		}
+
+		r, obj, err := s.store.Get(ctx, f.Key())
+		if err != nil {
+			fail(f, err)
+			continue
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			fail(f, err)
+			continue
+		}
+
+		blob, err := s.blobRepo.Acquire(ctx, &model.Blob{
+			ID:         hex.EncodeToString(h.Sum(nil)),
+			StorageKey: f.Key(),
+			Size:       obj.Size,
+			FileType:   f.FileType,
+			Variants:   f.Variants,
+		})
+		if err != nil {
+			fail(f, err)
+			continue
+		}
+
+		ownKey, ownVariants := f.Key(), f.Variants
+		f.SHA256 = blob.ID
+		f.StorageKey = blob.StorageKey
+		f.FileSize = blob.Size
+		f.Variants = blob.Variants
+		if err := s.repo.Update(f); err != nil {
+			s.blobRepo.Release(ctx, blob.ID)
+			fail(f, err)
+			continue
+		}
+		if blob.StorageKey != ownKey {
+			s.deleteKeys(ctx, ownKey, ownVariants)
+		}
+		report.Migrated++
+	}
+
+	if !dryRun {
+		log.Printf("Migrasi hash file: %d dari %d file dimigrasi, %d gagal",
+			report.Migrated, report.Total, len(report.Failed))
+	}
+	return c.JSON(report)
+}
+
+// GetDedupStats menampilkan jumlah blob, referensi, dan ruang yang dihemat
+func (s *fileService) GetDedupStats(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	stats, err := s.blobRepo.Stats(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to get deduplication stats",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    stats,
+	})
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/storage"
+)
+
+// jpegDenganExif membuat JPEG 300x200 dengan segmen EXIF berisi data rahasia
+func jpegDenganExif(t *testing.T, rahasia string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 300, 200)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	payload := append([]byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x00"), rahasia...)
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, seg...)
+	out = append(out, payload...)
+	return append(out, data[2:]...)
+}
+
+// File asli dan semua variant yang disimpan tidak boleh membawa EXIF
+func TestProcessImageBuangExif(t *testing.T) {
+	const rahasia = "GPS-LAT-6.2088-LNG-106.8456"
+	ctx := context.Background()
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fileService{store: store}
+
+	data := jpegDenganExif(t, rahasia)
+	clean, variants, err := s.processImage(ctx, "foto.jpg", "image/jpeg", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(clean, []byte("Exif")) || bytes.Contains(clean, []byte(rahasia)) {
+		t.Error("file asli masih membawa EXIF")
+	}
+
+	if len(variants) != len(model.UkuranVariant) {
+		t.Fatalf("variant = %v, want %d", variants, len(model.UkuranVariant))
+	}
+	for name, v := range variants {
+		r, _, err := store.Get(ctx, v.StorageKey)
+		if err != nil {
+			t.Fatalf("variant %s tidak tersimpan: %v", name, err)
+		}
+		stored, _ := io.ReadAll(r)
+		r.Close()
+		if bytes.Contains(stored, []byte("Exif")) || bytes.Contains(stored, []byte(rahasia)) {
+			t.Errorf("variant %s masih membawa EXIF", name)
+		}
+		if max := model.UkuranVariant[name]; v.Width > max[0] || v.Height > max[1] {
+			t.Errorf("variant %s %dx%d melebihi %v", name, v.Width, v.Height, max)
+		}
+	}
+}
@@ -0,0 +1,506 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/storage"
+	"praktikummongo/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Upload bertahap untuk file besar: init -> kirim potongan -> complete.
+// Setiap potongan dikirim sebagai body mentah sehingga tetap di bawah
+// BodyLimit aplikasi, dan potongan yang gagal cukup dikirim ulang.
+const (
+	defaultChunkSize = 5 << 20
+	minChunkSize     = 256 << 10
+	maxChunkSize     = 8 << 20
+
+	// assembleTimeout membatasi satu proses complete; klaim assembling yang lebih
+	// lama dari staleAssembling dianggap mati dan boleh diambil alih atau dibatalkan
+	assembleTimeout = 10 * time.Minute
+	staleAssembling = assembleTimeout + 5*time.Minute
+)
+
+// assemblingStale: sesi tertahan di assembling karena proses complete crash / timeout
+func assemblingStale(u *model.UploadSession) bool {
+	return u.Status == model.UploadAssembling &&
+		(u.AssemblingAt == nil || time.Since(*u.AssemblingAt) > staleAssembling)
+}
+
+var sha256Re = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// loadUploadSession mengambil sesi dari :id; hanya pembuat sesi dan admin yang boleh
+func (s *fileService) loadUploadSession(ctx context.Context, c *fiber.Ctx) (*model.UploadSession, error) {
+	u, err := s.uploadRepo.GetByID(ctx, c.Params("id"))
+	if err != nil || u == nil {
+		return nil, &fileError{fiber.StatusNotFound, "Upload session not found", nil}
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	if role != "admin" && (u.UploadedBy == nil || u.UploadedBy.Hex() != userID) {
+		return nil, &fileError{fiber.StatusForbidden, "You are not allowed to access this upload session", nil}
+	}
+	return u, nil
+}
+
+// missingChunks mengembalikan index potongan yang belum diterima
+func missingChunks(u *model.UploadSession) []int {
+	received := make(map[int]bool, len(u.Received))
+	for _, i := range u.Received {
+		received[i] = true
+	}
+	missing := []int{}
+	for i := 0; i < u.TotalChunks; i++ {
+		if !received[i] {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// chunkLength adalah ukuran yang diharapkan untuk potongan ke-index
+func chunkLength(u *model.UploadSession, index int) int64 {
+	if index == u.TotalChunks-1 {
+		return u.Size - int64(index)*u.ChunkSize
+	}
+	return u.ChunkSize
+}
+
+// CreateUploadSession memulai upload bertahap.
+// Body: file_name, content_type, size, sha256 (hex), chunk_size, category, entity_type, entity_id
+func (s *fileService) CreateUploadSession(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var req model.UploadSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	category, ok := validCategory(req.EntityType, req.Category)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid category",
+			"allowed": model.KategoriFile[req.EntityType],
+		})
+	}
+	if req.FileName == "" || req.Size <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "file_name and size are required",
+		})
+	}
+	if req.Size > s.batasUkuran(category) {
+		return s.sendError(c, s.errUkuran(category))
+	}
+	if err := s.cekKuota(ctx, c, req.Size); err != nil {
+		return s.sendError(c, err)
+	}
+	if !sha256Re.MatchString(req.SHA256) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "sha256 must be a lowercase hex SHA-256 digest",
+		})
+	}
+	if req.ChunkSize == 0 {
+		req.ChunkSize = defaultChunkSize
+	}
+	if req.ChunkSize < minChunkSize || req.ChunkSize > maxChunkSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("chunk_size must be between %s and %s",
+				utils.FormatUkuran(minChunkSize), utils.FormatUkuran(maxChunkSize)),
+		})
+	}
+
+	u := &model.UploadSession{
+		OriginalName: req.FileName,
+		ContentType:  req.ContentType,
+		Size:         req.Size,
+		ChunkSize:    req.ChunkSize,
+		TotalChunks:  int((req.Size + req.ChunkSize - 1) / req.ChunkSize),
+		SHA256:       req.SHA256,
+		Category:     category,
+		UploadedBy:   uploaderID(c),
+		Received:     []int{},
+		Status:       model.UploadActive,
+		CreatedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(s.sessionTTL),
+	}
+	if req.EntityType != "" || req.EntityID != "" {
+		objID, err := s.checkEntityAccess(c, req.EntityType, req.EntityID)
+		if err != nil {
+			return s.sendError(c, err)
+		}
+		u.EntityType, u.EntityID = req.EntityType, objID
+	}
+
+	if _, err := s.uploadRepo.Create(ctx, u); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create upload session",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Upload session created",
+		"data":    u,
+	})
+}
+
+// GetUploadSession menampilkan status sesi beserta potongan yang belum diterima,
+// dipakai klien untuk melanjutkan upload yang terputus
+func (s *fileService) GetUploadSession(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	u, err := s.loadUploadSession(ctx, c)
+	if err != nil {
+		return s.sendError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    u,
+		"missing": missingChunks(u),
+	})
+}
+
+// UploadChunk menyimpan satu potongan dari body mentah request.
+// Header X-Chunk-SHA256 opsional untuk memeriksa potongan itu sendiri.
+func (s *fileService) UploadChunk(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	u, err := s.loadUploadSession(ctx, c)
+	if err != nil {
+		return s.sendError(c, err)
+	}
+	if u.Status != model.UploadActive {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": "Upload session is " + u.Status,
+		})
+	}
+
+	index, err := c.ParamsInt("index")
+	if err != nil || index < 0 || index >= u.TotalChunks {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Chunk index must be between 0 and %d", u.TotalChunks-1),
+		})
+	}
+
+	body := c.Body()
+	if want := chunkLength(u, index); int64(len(body)) != want {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":  false,
+			"message":  "Chunk size does not match the session",
+			"expected": want,
+			"received": len(body),
+		})
+	}
+	if sum := c.Get("X-Chunk-SHA256"); sum != "" {
+		got := sha256.Sum256(body)
+		if hex.EncodeToString(got[:]) != sum {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"success": false,
+				"message": "Chunk checksum mismatch",
+			})
+		}
+	}
+
+	// Potongan pertama sudah cukup untuk menolak tipe file yang tidak diizinkan
+	if index == 0 {
+		fileType := utils.DetectFileType(body)
+		if fileType == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "File type not allowed",
+			})
+		}
+		if !fileType.MatchesContentType(u.ContentType) || !fileType.MatchesName(u.OriginalName) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success":  false,
+				"message":  "File content does not match its declared type or extension",
+				"detected": fileType.MIME,
+			})
+		}
+	}
+
+	if err := s.store.Put(ctx, u.ChunkKey(index), bytes.NewReader(body), int64(len(body)), "application/octet-stream"); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save chunk",
+			"error":   err.Error(),
+		})
+	}
+	if err := s.uploadRepo.MarkChunk(ctx, u.ID, index, time.Now().Add(s.sessionTTL)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to update upload session",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Chunk uploaded",
+		"data":    fiber.Map{"index": index, "total_chunks": u.TotalChunks},
+	})
+}
+
+// CompleteUpload menggabungkan semua potongan, memeriksa SHA-256 file utuh,
+// lalu menyimpannya seperti upload biasa. Aman dipanggil ulang setelah selesai.
+func (s *fileService) CompleteUpload(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), assembleTimeout)
+	defer cancel()
+
+	u, err := s.loadUploadSession(ctx, c)
+	if err != nil {
+		return s.sendError(c, err)
+	}
+
+	if u.Status == model.UploadCompleted && u.FileID != nil {
+		file, err := s.repo.FindByID(u.FileID.Hex())
+		if err != nil || file == nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": "File not found",
+			})
+		}
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "File uploaded successfully",
+			"data":    s.toFileResponse(file),
+		})
+	}
+
+	if missing := missingChunks(u); len(missing) > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": "Upload is not complete",
+			"missing": missing,
+		})
+	}
+	if u.EntityID != nil {
+		if _, err := s.checkEntityAccess(c, u.EntityType, u.EntityID.Hex()); err != nil {
+			return s.sendError(c, err)
+		}
+	}
+
+	// Kuota diperiksa ulang karena user bisa mengupload file lain selama sesi berjalan
+	if err := s.cekKuota(ctx, c, u.Size); err != nil {
+		return s.sendError(c, err)
+	}
+
+	claimed, err := s.uploadRepo.ClaimAssembling(ctx, u.ID, time.Now().Add(-staleAssembling))
+	if err != nil || !claimed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": "Upload session is already being completed",
+		})
+	}
+
+	r := &chunkReader{ctx: ctx, store: s.store, session: u}
+	fileModel, err := s.simpanBlob(ctx, u.OriginalName, u.ContentType, u.Size, r)
+	// Potongan yang sedang dibaca tetap terbuka jika simpanBlob berhenti di tengah
+	r.Close()
+	if err != nil {
+		s.releaseAssembling(u)
+		return s.sendError(c, err)
+	}
+
+	// simpanBlob sudah menghitung SHA-256 dari isi yang digabung
+	if fileModel.SHA256 != u.SHA256 {
+		// Potongan rusak tidak bisa diketahui yang mana, jadi semua dikirim ulang
+		s.deleteStored(ctx, fileModel)
+		s.deleteChunks(ctx, u)
+		s.uploadRepo.ResetChunks(ctx, u.ID)
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"message": "Checksum mismatch, please upload all chunks again",
+		})
+	}
+
+	fileModel.Category = u.Category
+	fileModel.EntityType = u.EntityType
+	fileModel.EntityID = u.EntityID
+	fileModel.UploadedBy = u.UploadedBy
+	fileModel.ScanStatus = model.ScanPending
+	if err := s.repo.Create(fileModel); err != nil {
+		s.deleteStored(ctx, fileModel)
+		s.releaseAssembling(u)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save file metadata",
+			"error":   err.Error(),
+		})
+	}
+
+	s.scans.Enqueue(fileModel.ID)
+
+	if err := s.uploadRepo.Complete(ctx, u.ID, fileModel.ID); err != nil {
+		log.Println("Gagal menandai sesi upload selesai:", err)
+	}
+	s.deleteChunks(ctx, u)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "File uploaded successfully",
+		"data":    s.toFileResponse(fileModel),
+	})
+}
+
+// AbortUpload membatalkan sesi dan menghapus potongan yang sudah diterima
+func (s *fileService) AbortUpload(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	u, err := s.loadUploadSession(ctx, c)
+	if err != nil {
+		return s.sendError(c, err)
+	}
+	if u.Status == model.UploadAssembling && !assemblingStale(u) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": "Upload session is being completed",
+		})
+	}
+
+	s.deleteChunks(ctx, u)
+	if err := s.uploadRepo.Delete(ctx, u.ID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to delete upload session",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Upload session aborted",
+	})
+}
+
+// releaseAssembling mengaktifkan lagi sesi setelah complete gagal. Memakai context
+// baru karena ctx complete bisa saja sudah habis waktunya.
+func (s *fileService) releaseAssembling(u *model.UploadSession) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := s.uploadRepo.SetStatus(ctx, u.ID, model.UploadAssembling, model.UploadActive); err != nil {
+		log.Println("Gagal mengaktifkan lagi sesi upload:", err)
+	}
+}
+
+func (s *fileService) deleteChunks(ctx context.Context, u *model.UploadSession) {
+	for i := 0; i < u.TotalChunks; i++ {
+		if err := s.store.Delete(ctx, u.ChunkKey(i)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Println("Gagal menghapus potongan upload:", err)
+		}
+	}
+}
+
+// StartUploadPurge menghapus sesi upload yang ditinggalkan (melewati expires_at)
+// beserta potongannya, setiap jam sampai ctx dibatalkan
+func (s *fileService) StartUploadPurge(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for {
+			s.purgeUploads(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *fileService) purgeUploads(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	sessions, err := s.uploadRepo.GetExpired(ctx, time.Now())
+	if err != nil {
+		log.Println("Purge sesi upload gagal:", err)
+		return
+	}
+	for i := range sessions {
+		s.deleteChunks(ctx, &sessions[i])
+		if err := s.uploadRepo.Delete(ctx, sessions[i].ID); err != nil {
+			log.Println("Purge sesi upload gagal:", err)
+		}
+	}
+	if len(sessions) > 0 {
+		log.Printf("Purge sesi upload: %d sesi kedaluwarsa dihapus", len(sessions))
+	}
+}
+
+// chunkReader membaca potongan sesi secara berurutan sebagai satu stream
+type chunkReader struct {
+	ctx     context.Context
+	store   storage.Storage
+	session *model.UploadSession
+	next    int
+	cur     io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if r.next >= r.session.TotalChunks {
+				return 0, io.EOF
+			}
+			f, _, err := r.store.Get(r.ctx, r.session.ChunkKey(r.next))
+			if err != nil {
+				return 0, fmt.Errorf("potongan %d: %w", r.next, err)
+			}
+			r.cur = f
+			r.next++
+		}
+
+		n, err := r.cur.Read(p)
+		if err == io.EOF {
+			r.cur.Close()
+			r.cur = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close menutup potongan yang sedang dibaca; aman dipanggil setelah EOF
+func (r *chunkReader) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+	err := r.cur.Close()
+	r.cur = nil
+	return err
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/storage"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countingStorage menghitung reader dari Get yang belum ditutup
+type countingStorage struct {
+	storage.Storage
+	open int
+}
+
+type countingReader struct {
+	io.ReadSeekCloser
+	s *countingStorage
+}
+
+func (r *countingReader) Close() error {
+	r.s.open--
+	return r.ReadSeekCloser.Close()
+}
+
+func (s *countingStorage) Get(ctx context.Context, key string) (io.ReadSeekCloser, *storage.Object, error) {
+	r, obj, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.open++
+	return &countingReader{r, s}, obj, nil
+}
+
+func chunkSession(t *testing.T, parts ...string) (*countingStorage, *model.UploadSession) {
+	t.Helper()
+	local, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &countingStorage{Storage: local}
+	u := &model.UploadSession{ID: primitive.NewObjectID(), TotalChunks: len(parts)}
+	for i, p := range parts {
+		if err := local.Put(context.Background(), u.ChunkKey(i), strings.NewReader(p), int64(len(p)), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store, u
+}
+
+func TestChunkReaderGabung(t *testing.T) {
+	store, u := chunkSession(t, "abc", "def", "g")
+	r := &chunkReader{ctx: context.Background(), store: store, session: u}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "abcdefg" {
+		t.Errorf("isi = %q, want abcdefg", data)
+	}
+	if err := r.Close(); err != nil || store.open != 0 {
+		t.Errorf("Close = %v, reader terbuka %d", err, store.open)
+	}
+}
+
+// Konsumen yang berhenti di tengah (misalnya tipe file ditolak) tidak boleh
+// meninggalkan potongan yang terbuka
+func TestChunkReaderCloseDiTengah(t *testing.T) {
+	store, u := chunkSession(t, "abc", "def")
+	r := &chunkReader{ctx: context.Background(), store: store, session: u}
+
+	if _, err := io.ReadFull(r, make([]byte, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if store.open != 1 {
+		t.Fatalf("reader terbuka = %d, want 1", store.open)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if store.open != 0 {
+		t.Errorf("reader terbuka setelah Close = %d, want 0", store.open)
+	}
+}
+
+func TestAssemblingStale(t *testing.T) {
+	baru := time.Now().Add(-time.Minute)
+	lama := time.Now().Add(-staleAssembling - time.Minute)
+	tests := []struct {
+		u    model.UploadSession
+		want bool
+	}{
+		{model.UploadSession{Status: model.UploadActive}, false},
+		{model.UploadSession{Status: model.UploadAssembling, AssemblingAt: &baru}, false},
+		{model.UploadSession{Status: model.UploadAssembling, AssemblingAt: &lama}, true},
+		// Sesi dari sebelum ada assembling_at
+		{model.UploadSession{Status: model.UploadAssembling}, true},
+	}
+	for _, tt := range tests {
+		if got := assemblingStale(&tt.u); got != tt.want {
+			t.Errorf("assemblingStale(%s, %v) = %v, want %v", tt.u.Status, tt.u.AssemblingAt, got, tt.want)
+		}
+	}
+}
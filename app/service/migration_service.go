@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+	"praktikummongo/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MigrationService berisi migrasi data satu kali yang dijalankan admin.
+// Semua migrasi mendukung ?dry_run=true untuk melihat laporan tanpa menulis data.
+type MigrationService struct {
+	pekerjaanRepo repository.IPekerjaanRepository
+	taksonomiRepo repository.ITaksonomiRepository
+	cache         *StatsCacheService
+}
+
+func NewMigrationService(pekerjaanRepo repository.IPekerjaanRepository, taksonomiRepo repository.ITaksonomiRepository, cache *StatsCacheService) *MigrationService {
+	return &MigrationService{pekerjaanRepo: pekerjaanRepo, taksonomiRepo: taksonomiRepo, cache: cache}
+}
+
+// ------------------- Tanggal Pekerjaan -------------------
+
+// MigrateTanggalPekerjaan mengubah tanggal_mulai_kerja / tanggal_selesai_kerja
+// yang masih string menjadi tipe date. Baris yang gagal diparse tidak diubah
+// dan dilaporkan di field "failed".
+func (s *MigrationService) MigrateTanggalPekerjaan(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report, err := s.migrateTanggal(ctx, c.QueryBool("dry_run", false))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// MigrateTanggalOnStartup menjalankan migrasi tanggal saat aplikasi start, karena
+// dokumen dengan tanggal string gagal didecode ke time.Time dan membuat listing
+// pekerjaan error. Baris yang tetap gagal dicatat ke log untuk diperbaiki manual.
+func (s *MigrationService) MigrateTanggalOnStartup(ctx context.Context) error {
+	report, err := s.migrateTanggal(ctx, false)
+	if err != nil {
+		return err
+	}
+	for _, f := range report.Failed {
+		log.Printf("Tanggal pekerjaan %s (%s = %q) tidak bisa dimigrasi: %s", f.ID.Hex(), f.Field, f.Value, f.Reason)
+	}
+	return nil
+}
+
+func (s *MigrationService) migrateTanggal(ctx context.Context, dryRun bool) (*model.MigrationResult, error) {
+	rows, err := s.pekerjaanRepo.GetLegacyTanggal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.MigrationResult{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Failed: []model.MigrationFail{},
+	}
+
+	for _, row := range rows {
+		mulai, errMulai := legacyTanggal(row.TanggalMulaiKerja, false)
+		selesai, errSelesai := legacyTanggal(row.TanggalSelesaiKerja, true)
+		if errMulai == nil && mulai == nil {
+			errMulai = fmt.Errorf("tanggal mulai kosong")
+		}
+
+		failed := false
+		if errMulai != nil {
+			report.Failed = append(report.Failed, migrationFail(row.ID, "tanggal_mulai_kerja", row.TanggalMulaiKerja, errMulai))
+			failed = true
+		}
+		if errSelesai != nil {
+			report.Failed = append(report.Failed, migrationFail(row.ID, "tanggal_selesai_kerja", row.TanggalSelesaiKerja, errSelesai))
+			failed = true
+		}
+		if failed {
+			continue
+		}
+
+		if !dryRun {
+			if err := s.pekerjaanRepo.SetTanggal(ctx, row.ID, *mulai, selesai); err != nil {
+				report.Failed = append(report.Failed, migrationFail(row.ID, "*", nil, err))
+				continue
+			}
+		}
+		report.Migrated++
+	}
+
+	if !dryRun && report.Total > 0 {
+		log.Printf("Migrasi tanggal pekerjaan: %d dari %d baris dimigrasi, %d gagal",
+			report.Migrated, report.Total, len(report.Failed))
+	}
+	if !dryRun && report.Migrated > 0 {
+		s.cache.Invalidate()
+	}
+	return report, nil
+}
+
+// ------------------- Gaji Pekerjaan -------------------
+
+// MigrateGajiPekerjaan mengubah gaji_range string (misalnya "5-10 juta") menjadi
+// field gaji terstruktur. Baris yang gagal diparse tidak diubah dan dilaporkan.
+func (s *MigrationService) MigrateGajiPekerjaan(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	dryRun := c.QueryBool("dry_run", false)
+
+	rows, err := s.pekerjaanRepo.GetLegacyGaji(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+
+	report := model.MigrationResult{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Failed: []model.MigrationFail{},
+	}
+
+	for _, row := range rows {
+		var gaji *model.Gaji
+		if strings.TrimSpace(row.GajiRange) != "" {
+			if gaji, err = utils.ParseGajiRange(row.GajiRange); err != nil {
+				report.Failed = append(report.Failed, migrationFail(row.ID, "gaji_range", row.GajiRange, err))
+				continue
+			}
+		}
+
+		if !dryRun {
+			if err := s.pekerjaanRepo.SetGaji(ctx, row.ID, gaji); err != nil {
+				report.Failed = append(report.Failed, migrationFail(row.ID, "*", nil, err))
+				continue
+			}
+		}
+		report.Migrated++
+	}
+
+	if !dryRun {
+		log.Printf("Migrasi gaji pekerjaan: %d dari %d baris dimigrasi, %d gagal",
+			report.Migrated, report.Total, len(report.Failed))
+	}
+	if !dryRun && report.Migrated > 0 {
+		s.cache.Invalidate()
+	}
+	return c.JSON(report)
+}
+
+// ------------------- Taksonomi Pekerjaan -------------------
+
+// MigrateTaksonomiPekerjaan memetakan status_pekerjaan dan bidang_industri lama ke
+// kode baku lewat tabel alias taksonomi. Nilai yang tidak dikenal dilaporkan agar
+// admin bisa menambahkan alias lalu menjalankan ulang migrasi.
+func (s *MigrationService) MigrateTaksonomiPekerjaan(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	dryRun := c.QueryBool("dry_run", false)
+
+	rows, err := s.pekerjaanRepo.GetTaksonomiValues(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+
+	report := model.MigrationResult{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Failed: []model.MigrationFail{},
+	}
+
+	for _, row := range rows {
+		status, errStatus := resolveTaksonomi(ctx, s.taksonomiRepo, model.JenisStatusPekerjaan, row.StatusPekerjaan)
+		bidang, errBidang := resolveTaksonomi(ctx, s.taksonomiRepo, model.JenisBidangIndustri, row.BidangIndustri)
+		if errStatus != nil {
+			report.Failed = append(report.Failed, migrationFail(row.ID, "status_pekerjaan", row.StatusPekerjaan, errStatus))
+		}
+		if errBidang != nil {
+			report.Failed = append(report.Failed, migrationFail(row.ID, "bidang_industri", row.BidangIndustri, errBidang))
+		}
+		if errStatus != nil || errBidang != nil {
+			continue
+		}
+
+		changed := status != row.StatusPekerjaan || bidang != row.BidangIndustri
+		if changed && !dryRun {
+			if err := s.pekerjaanRepo.SetTaksonomi(ctx, row.ID, status, bidang); err != nil {
+				report.Failed = append(report.Failed, migrationFail(row.ID, "*", nil, err))
+				continue
+			}
+		}
+		report.Migrated++
+	}
+
+	if !dryRun {
+		log.Printf("Migrasi taksonomi pekerjaan: %d dari %d baris dimigrasi, %d gagal",
+			report.Migrated, report.Total, len(report.Failed))
+	}
+	if !dryRun && report.Migrated > 0 {
+		s.cache.Invalidate()
+	}
+	return c.JSON(report)
+}
+
+// legacyTanggal membaca nilai tanggal lama yang bisa berupa string, date, atau null
+func legacyTanggal(v interface{}, opsional bool) (*time.Time, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case primitive.DateTime:
+		t := val.Time().UTC()
+		return &t, nil
+	case string:
+		if opsional {
+			return utils.ParseTanggalOpsional(val)
+		}
+		if val == "" {
+			return nil, nil
+		}
+		t, err := utils.ParseTanggal(val)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	default:
+		return nil, fmt.Errorf("tipe %T tidak didukung", v)
+	}
+}
+
+func migrationFail(id primitive.ObjectID, field string, value interface{}, err error) model.MigrationFail {
+	return model.MigrationFail{
+		ID:     id,
+		Field:  field,
+		Value:  fmt.Sprint(value),
+		Reason: err.Error(),
+	}
+}
@@ -2,30 +2,75 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"praktikummongo/app/model"
 	"praktikummongo/app/repository"
+	"praktikummongo/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive" // <-- TAMBAHKAN IMPORT INI
 )
 
 type PekerjaanService struct {
-	repo repository.IPekerjaanRepository
+	repo          repository.IPekerjaanRepository
+	alumniRepo    repository.IAlumniRepository
+	taksonomiRepo repository.ITaksonomiRepository
+	cache         *StatsCacheService
 }
 
-func NewPekerjaanService(repo repository.IPekerjaanRepository) *PekerjaanService {
-	return &PekerjaanService{repo: repo}
+func NewPekerjaanService(repo repository.IPekerjaanRepository, alumniRepo repository.IAlumniRepository, taksonomiRepo repository.ITaksonomiRepository, cache *StatsCacheService) *PekerjaanService {
+	return &PekerjaanService{repo: repo, alumniRepo: alumniRepo, taksonomiRepo: taksonomiRepo, cache: cache}
 }
 
 // ------------------- CRUD Dasar -------------------
 
+// includeDeletedQuery membaca ?include_deleted=true. Hanya admin yang boleh
+// melihat data di trash lewat endpoint biasa.
+func includeDeletedQuery(c *fiber.Ctx) (bool, bool) {
+	if !c.QueryBool("include_deleted", false) {
+		return false, true
+	}
+	role, _ := c.Locals("role").(string)
+	return true, role == "admin"
+}
+
+// parsePekerjaanFilter membaca query aktif_pada, mulai_dari, mulai_sampai
+func parsePekerjaanFilter(c *fiber.Ctx, includeDeleted bool) (model.PekerjaanFilter, error) {
+	filter := model.PekerjaanFilter{IncludeDeleted: includeDeleted}
+	for param, dst := range map[string]**time.Time{
+		"aktif_pada":   &filter.AktifPada,
+		"mulai_dari":   &filter.MulaiDari,
+		"mulai_sampai": &filter.MulaiSampai,
+	} {
+		if v := c.Query(param); v != "" {
+			t, err := utils.ParseTanggal(v)
+			if err != nil {
+				return filter, fmt.Errorf("%s tidak valid: %w", param, err)
+			}
+			*dst = &t
+		}
+	}
+	return filter, nil
+}
+
 func (s *PekerjaanService) GetAll(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	list, err := s.repo.GetAll(ctx)
+	includeDeleted, allowed := includeDeletedQuery(c)
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Hanya admin yang boleh melihat data terhapus"})
+	}
+
+	filter, err := parsePekerjaanFilter(c, includeDeleted)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	list, err := s.repo.GetAll(ctx, filter)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
 	}
@@ -36,8 +81,13 @@ func (s *PekerjaanService) GetByID(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	includeDeleted, allowed := includeDeletedQuery(c)
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Hanya admin yang boleh melihat data terhapus"})
+	}
+
 	id := c.Params("id")
-	data, err := s.repo.GetByID(ctx, id)
+	data, err := s.repo.GetByID(ctx, id, includeDeleted)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
 	}
@@ -64,22 +114,116 @@ func (s *PekerjaanService) GetByAlumniID(c *fiber.Ctx) error {
 	return c.JSON(list)
 }
 
+// GetByAlumniParam mengembalikan riwayat karier alumni pada GET /alumni/:id/pekerjaan
+func (s *PekerjaanService) GetByAlumniParam(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id := c.Params("id")
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Alumni ID tidak valid"})
+	}
+
+	alumni, err := s.alumniRepo.GetByID(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	if alumni == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
+	}
+
+	list, err := s.repo.GetByAlumniID(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	return c.JSON(list)
+}
+
+// parsePekerjaanInput membaca body, mengubah tanggal string menjadi time.Time,
+// dan memvalidasi gaji (gaji_range lama diparse jika gaji tidak dikirim)
+func parsePekerjaanInput(c *fiber.Ctx) (*model.PekerjaanAlumni, error) {
+	var in model.PekerjaanInput
+	if err := c.BodyParser(&in); err != nil {
+		return nil, err
+	}
+
+	mulai, err := utils.ParseTanggal(in.TanggalMulaiKerja)
+	if err != nil {
+		return nil, fmt.Errorf("tanggal_mulai_kerja: %w", err)
+	}
+	selesai, err := utils.ParseTanggalOpsional(in.TanggalSelesaiKerja)
+	if err != nil {
+		return nil, fmt.Errorf("tanggal_selesai_kerja: %w", err)
+	}
+	if selesai != nil && selesai.Before(mulai) {
+		return nil, errors.New("tanggal_selesai_kerja tidak boleh sebelum tanggal_mulai_kerja")
+	}
+
+	gaji := in.Gaji
+	if gaji == nil && in.GajiRange != "" {
+		if gaji, err = utils.ParseGajiRange(in.GajiRange); err != nil {
+			return nil, fmt.Errorf("gaji_range: %w", err)
+		}
+	}
+	if gaji != nil {
+		if gaji.Currency == "" {
+			gaji.Currency = "IDR"
+		}
+		if gaji.Period == "" {
+			gaji.Period = "bulan"
+		}
+		if err := utils.ValidateGaji(gaji); err != nil {
+			return nil, fmt.Errorf("gaji: %w", err)
+		}
+	}
+
+	return &model.PekerjaanAlumni{
+		AlumniID:            in.AlumniID,
+		NamaPerusahaan:      in.NamaPerusahaan,
+		PosisiJabatan:       in.PosisiJabatan,
+		BidangIndustri:      in.BidangIndustri,
+		LokasiKerja:         in.LokasiKerja,
+		Gaji:                gaji,
+		TanggalMulaiKerja:   mulai,
+		TanggalSelesaiKerja: selesai,
+		StatusPekerjaan:     in.StatusPekerjaan,
+		DeskripsiPekerjaan:  in.DeskripsiPekerjaan,
+	}, nil
+}
+
+// normalizeTaksonomi mengganti status_pekerjaan dan bidang_industri dengan kode baku
+func (s *PekerjaanService) normalizeTaksonomi(ctx context.Context, p *model.PekerjaanAlumni) error {
+	var err error
+	if p.StatusPekerjaan, err = resolveTaksonomi(ctx, s.taksonomiRepo, model.JenisStatusPekerjaan, p.StatusPekerjaan); err != nil {
+		return err
+	}
+	if p.BidangIndustri, err = resolveTaksonomi(ctx, s.taksonomiRepo, model.JenisBidangIndustri, p.BidangIndustri); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *PekerjaanService) Create(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var p model.PekerjaanAlumni
-	if err := c.BodyParser(&p); err != nil {
+	p, err := parsePekerjaanInput(c)
+	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Input tidak valid", "detail": err.Error()})
 	}
 
+	if err := s.normalizeTaksonomi(ctx, p); err != nil {
+		return taksonomiError(c, err)
+	}
+
 	p.CreatedAt = time.Now()
 	p.UpdatedAt = time.Now()
 
-	newData, err := s.repo.Create(ctx, &p)
+	newData, err := s.repo.Create(ctx, p)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal menambah data", "detail": err.Error()})
 	}
+	s.cache.Invalidate()
 	return c.Status(201).JSON(newData)
 }
 
@@ -88,31 +232,31 @@ func (s *PekerjaanService) Update(c *fiber.Ctx) error {
 	defer cancel()
 
 	id := c.Params("id")
-	var p model.PekerjaanAlumni
-	if err := c.BodyParser(&p); err != nil {
+	p, err := parsePekerjaanInput(c)
+	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Input tidak valid", "detail": err.Error()})
 	}
 
+	if err := s.normalizeTaksonomi(ctx, p); err != nil {
+		return taksonomiError(c, err)
+	}
+
 	p.UpdatedAt = time.Now()
-	if err := s.repo.Update(ctx, id, &p); err != nil {
+	found, err := s.repo.Update(ctx, id, p)
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal memperbarui data", "detail": err.Error()})
 	}
-	return c.JSON(fiber.Map{"message": "Pekerjaan berhasil diupdate"})
-}
-
-func (s *PekerjaanService) Delete(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	id := c.Params("id")
-	if err := s.repo.HardDelete(ctx, id); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus data", "detail": err.Error()})
+	if !found {
+		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
 	}
-	return c.JSON(fiber.Map{"message": "Pekerjaan berhasil dihapus"})
+	s.cache.Invalidate()
+	return c.JSON(fiber.Map{"message": "Pekerjaan berhasil diupdate"})
 }
 
 // ------------------- RBAC (Soft Delete, Restore, Hard Delete) -------------------
 
+// DeleteRBAC adalah handler default DELETE /pekerjaan/:id: data dipindah ke trash,
+// bukan dihapus permanen. Hapus permanen lewat HardDelete.
 func (s *PekerjaanService) DeleteRBAC(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -129,8 +273,8 @@ func (s *PekerjaanService) DeleteRBAC(c *fiber.Ctx) error {
 	}
 	// --- END FIX 1 ---
 
-	ownerID, err := s.repo.GetOwnerID(ctx, id)
-	if err != nil {
+	ownerID, deleted, err := s.repo.GetOwnerAndDeleteStatus(ctx, id)
+	if err != nil || (deleted != nil && *deleted) {
 		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
 	}
 
@@ -144,6 +288,7 @@ func (s *PekerjaanService) DeleteRBAC(c *fiber.Ctx) error {
 	if err := s.repo.SoftDelete(ctx, id, userID); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal melakukan soft delete", "detail": err.Error()})
 	}
+	s.cache.Invalidate()
 
 	return c.JSON(fiber.Map{"message": "Pekerjaan berhasil dihapus (soft delete)"})
 }
@@ -168,16 +313,43 @@ func (s *PekerjaanService) GetTrash(c *fiber.Ctx) error {
 	}
 	// --- END FIX 3 ---
 
+	filter := model.TrashFilter{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+		Order: c.Query("order", "desc"),
+	}
+	if deletedBy := c.Query("deleted_by"); deletedBy != "" {
+		objID, err := primitive.ObjectIDFromHex(deletedBy)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "deleted_by tidak valid"})
+		}
+		filter.DeletedBy = &objID
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	}
+
 	// Kirim userObjID (yang sekarang bertipe *primitive.ObjectID)
-	data, err := s.repo.GetTrash(ctx, role, userObjID)
+	data, total, err := s.repo.GetTrash(ctx, role, userObjID, filter)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
 	}
-	if len(data) == 0 {
-		return c.Status(404).JSON(fiber.Map{"error": "Tidak ada pekerjaan yang dihapus"})
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + filter.Limit - 1) / filter.Limit
 	}
 
-	return c.JSON(data)
+	return c.JSON(fiber.Map{
+		"page":        filter.Page,
+		"limit":       filter.Limit,
+		"total":       total,
+		"total_pages": totalPages,
+		"data":        data,
+	})
 }
 
 func (s *PekerjaanService) Restore(c *fiber.Ctx) error {
@@ -188,6 +360,7 @@ func (s *PekerjaanService) Restore(c *fiber.Ctx) error {
 	if err := s.repo.Restore(ctx, id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal merestore data", "detail": err.Error()})
 	}
+	s.cache.Invalidate()
 	return c.JSON(fiber.Map{"message": "Pekerjaan berhasil direstore"})
 }
 
@@ -231,4 +404,135 @@ func (s *PekerjaanService) HardDelete(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{"message": "Pekerjaan berhasil dihapus permanen"})
-}	
\ No newline at end of file
+}
+
+// ------------------- Operasi Massal Trash -------------------
+
+// Batas jumlah item per request operasi massal
+const maxBulkTrash = 1000
+
+// BulkRestore merestore banyak pekerjaan sekaligus dari trash
+func (s *PekerjaanService) BulkRestore(c *fiber.Ctx) error {
+	return s.bulkTrash(c, "restored")
+}
+
+// BulkHardDelete menghapus permanen banyak pekerjaan sekaligus dari trash
+func (s *PekerjaanService) BulkHardDelete(c *fiber.Ctx) error {
+	return s.bulkTrash(c, "purged")
+}
+
+func (s *PekerjaanService) bulkTrash(c *fiber.Ctx, action string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "User ID tidak valid"})
+	}
+
+	var req model.BulkTrashRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Input tidak valid", "detail": err.Error()})
+	}
+
+	ids, err := s.resolveBulkTargets(ctx, req, role, userObjID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(ids) > maxBulkTrash {
+		return c.Status(400).JSON(fiber.Map{"error": "Terlalu banyak data dalam satu request", "max": maxBulkTrash})
+	}
+
+	results := make([]model.BulkTrashResult, 0, len(ids))
+	success := 0
+	for _, id := range ids {
+		res := model.BulkTrashResult{ID: id, Status: action}
+		if reason := s.checkTrashAccess(ctx, id, role, userObjID); reason != "" {
+			res.Status, res.Reason = "failed", reason
+			results = append(results, res)
+			continue
+		}
+
+		if action == "restored" {
+			err = s.repo.Restore(ctx, id)
+		} else {
+			err = s.repo.HardDelete(ctx, id)
+		}
+		if err != nil {
+			res.Status, res.Reason = "failed", err.Error()
+		} else {
+			success++
+		}
+		results = append(results, res)
+	}
+	// Pekerjaan di trash tidak ikut statistik; hanya restore yang mengubahnya
+	if action == "restored" && success > 0 {
+		s.cache.Invalidate()
+	}
+
+	return c.JSON(fiber.Map{
+		"total":   len(results),
+		"success": success,
+		"failed":  len(results) - success,
+		"results": results,
+	})
+}
+
+// resolveBulkTargets mengambil daftar ID dari body: ids eksplisit atau filter trash
+func (s *PekerjaanService) resolveBulkTargets(ctx context.Context, req model.BulkTrashRequest, role string, userObjID primitive.ObjectID) ([]string, error) {
+	if len(req.IDs) > 0 {
+		seen := make(map[string]bool, len(req.IDs))
+		ids := make([]string, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+	if req.Filter == nil {
+		return nil, errors.New("ids atau filter wajib diisi")
+	}
+
+	filter := model.TrashFilter{
+		DeletedAfter:  req.Filter.DeletedAfter,
+		DeletedBefore: req.Filter.DeletedBefore,
+	}
+	if req.Filter.DeletedBy != "" {
+		objID, err := primitive.ObjectIDFromHex(req.Filter.DeletedBy)
+		if err != nil {
+			return nil, errors.New("filter.deleted_by tidak valid")
+		}
+		filter.DeletedBy = &objID
+	}
+
+	objIDs, err := s.repo.GetTrashIDs(ctx, role, &userObjID, filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(objIDs))
+	for _, id := range objIDs {
+		ids = append(ids, id.Hex())
+	}
+	return ids, nil
+}
+
+// checkTrashAccess menerapkan aturan yang sama dengan HardDelete: data harus
+// sudah di trash dan user bukan admin hanya boleh mengakses miliknya sendiri.
+// Mengembalikan alasan penolakan, atau string kosong jika diizinkan.
+func (s *PekerjaanService) checkTrashAccess(ctx context.Context, id, role string, userObjID primitive.ObjectID) string {
+	ownerID, deleted, err := s.repo.GetOwnerAndDeleteStatus(ctx, id)
+	if err != nil {
+		return "Data tidak ditemukan"
+	}
+	if deleted == nil || !*deleted {
+		return "Data belum dihapus (soft delete)"
+	}
+	if role != "admin" && *ownerID != userObjID {
+		return "Anda tidak memiliki izin mengakses data ini"
+	}
+	return ""
+}
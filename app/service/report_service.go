@@ -0,0 +1,410 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportService membuat laporan PDF untuk akreditasi (profil alumni dan ringkasan angkatan).
+// PDF dibuat di server tanpa layanan eksternal lalu disimpan lewat FileService.
+type ReportService struct {
+	alumniRepo    repository.IAlumniRepository
+	pekerjaanRepo repository.IPekerjaanRepository
+	statsRepo     repository.IStatsRepository
+	taksonomiRepo repository.ITaksonomiRepository
+	files         FileService
+}
+
+func NewReportService(alumniRepo repository.IAlumniRepository, pekerjaanRepo repository.IPekerjaanRepository,
+	statsRepo repository.IStatsRepository, taksonomiRepo repository.ITaksonomiRepository, files FileService) *ReportService {
+	return &ReportService{
+		alumniRepo:    alumniRepo,
+		pekerjaanRepo: pekerjaanRepo,
+		statsRepo:     statsRepo,
+		taksonomiRepo: taksonomiRepo,
+		files:         files,
+	}
+}
+
+var namaBulan = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// tanggalIndonesia memformat tanggal menjadi "2 Januari 2006"
+func tanggalIndonesia(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), namaBulan[t.Month()-1], t.Year())
+}
+
+// formatRibuan memformat angka dengan pemisah ribuan titik, misalnya 5.000.000
+func formatRibuan(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "." + s[i:]
+	}
+	if neg {
+		return "-" + s
+	}
+	return s
+}
+
+// formatGaji menampilkan rentang gaji, misalnya "IDR 5.000.000 - 10.000.000 / bulan"
+func formatGaji(g *model.Gaji) string {
+	if g == nil {
+		return "-"
+	}
+	var rentang string
+	switch {
+	case g.Max == 0:
+		rentang = "> " + formatRibuan(g.Min)
+	case g.Min == 0:
+		rentang = "< " + formatRibuan(g.Max)
+	case g.Min == g.Max:
+		rentang = formatRibuan(g.Min)
+	default:
+		rentang = formatRibuan(g.Min) + " - " + formatRibuan(g.Max)
+	}
+	return fmt.Sprintf("%s %s / %s", g.Currency, rentang, g.Period)
+}
+
+// namaTaksonomi memetakan kode taksonomi ke nama tampilannya
+func (s *ReportService) namaTaksonomi(ctx context.Context, jenis string) (map[string]string, error) {
+	list, err := s.taksonomiRepo.GetAll(ctx, jenis)
+	if err != nil {
+		return nil, err
+	}
+	nama := make(map[string]string, len(list))
+	for _, t := range list {
+		nama[t.Kode] = t.Nama
+	}
+	return nama, nil
+}
+
+// istilah mengembalikan nama dari map taksonomi, atau nilai aslinya jika tidak ada
+func istilah(nama map[string]string, kode string) string {
+	if n, ok := nama[kode]; ok {
+		return n
+	}
+	if kode == "" {
+		return "-"
+	}
+	return kode
+}
+
+// ------------------- PDF -------------------
+
+// laporanPDF membungkus fpdf dengan helper judul dan tabel
+type laporanPDF struct {
+	pdf *fpdf.Fpdf
+	tr  func(string) string
+}
+
+func newLaporanPDF(judul string) *laporanPDF {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetTitle(judul, true)
+	pdf.SetCreator("Sistem Tracer Study Alumni", true)
+
+	l := &laporanPDF{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, l.tr(fmt.Sprintf("Dicetak %s - Halaman %d", tanggalIndonesia(time.Now()), pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 15)
+	pdf.MultiCell(0, 8, l.tr(judul), "", "C", false)
+	pdf.Ln(4)
+	return l
+}
+
+func (l *laporanPDF) subjudul(teks string) {
+	l.pdf.Ln(3)
+	l.pdf.SetFont("Helvetica", "B", 12)
+	l.pdf.CellFormat(0, 7, l.tr(teks), "", 1, "L", false, 0, "")
+}
+
+// keterangan menulis pasangan label : nilai
+func (l *laporanPDF) keterangan(rows [][2]string) {
+	for _, r := range rows {
+		l.pdf.SetFont("Helvetica", "B", 10)
+		l.pdf.CellFormat(45, 6, l.tr(r[0]), "", 0, "L", false, 0, "")
+		l.pdf.SetFont("Helvetica", "", 10)
+		l.pdf.MultiCell(0, 6, l.tr(": "+r[1]), "", "L", false)
+	}
+}
+
+func (l *laporanPDF) catatan(teks string) {
+	l.pdf.SetFont("Helvetica", "I", 9)
+	l.pdf.MultiCell(0, 5, l.tr(teks), "", "L", false)
+}
+
+// tabel menulis tabel dengan header; lebar kolom dalam mm
+func (l *laporanPDF) tabel(header []string, lebar []float64, rows [][]string) {
+	if len(rows) == 0 {
+		l.catatan("Belum ada data.")
+		return
+	}
+
+	cetakHeader := func() {
+		l.pdf.SetFont("Helvetica", "B", 9)
+		l.pdf.SetFillColor(225, 225, 225)
+		for i, h := range header {
+			l.pdf.CellFormat(lebar[i], 7, l.tr(h), "1", 0, "C", true, 0, "")
+		}
+		l.pdf.Ln(-1)
+		l.pdf.SetFont("Helvetica", "", 9)
+	}
+
+	cetakHeader()
+	_, pageH := l.pdf.GetPageSize()
+	_, _, _, bottom := l.pdf.GetMargins()
+	for _, row := range rows {
+		// Header diulang di halaman baru
+		if l.pdf.GetY()+6 > pageH-bottom {
+			l.pdf.AddPage()
+			cetakHeader()
+		}
+		for i, v := range row {
+			align := "L"
+			if _, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSuffix(v, "%"), ".", ""), 64); err == nil {
+				align = "R"
+			}
+			l.pdf.CellFormat(lebar[i], 6, l.tr(potongTeks(l.pdf, v, lebar[i])), "1", 0, align, false, 0, "")
+		}
+		l.pdf.Ln(-1)
+	}
+}
+
+// potongTeks memendekkan teks agar muat di satu sel
+func potongTeks(pdf *fpdf.Fpdf, s string, lebar float64) string {
+	if pdf.GetStringWidth(s) <= lebar-2 {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && pdf.GetStringWidth(string(r)+"...") > lebar-2 {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "..."
+}
+
+func (l *laporanPDF) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := l.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// simpan menyimpan PDF lewat FileService dan mengirim metadata file.
+// entityID diisi untuk laporan milik satu alumni.
+func (s *ReportService) simpan(c *fiber.Ctx, l *laporanPDF, fileName string, entityID *primitive.ObjectID) error {
+	data, err := l.bytes()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat PDF", "detail": err.Error()})
+	}
+
+	meta := &model.File{
+		OriginalName: fileName,
+		FileType:     "application/pdf",
+		Category:     "laporan",
+		UploadedBy:   uploaderID(c),
+	}
+	if entityID != nil {
+		meta.EntityType = model.EntityAlumni
+		meta.EntityID = entityID
+	}
+	file, err := s.files.SaveGenerated(meta, data)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan laporan", "detail": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "Laporan berhasil dibuat", "data": file})
+}
+
+// ------------------- Handler -------------------
+
+// ProfilAlumni membuat PDF profil satu alumni beserta riwayat pekerjaannya
+func (s *ReportService) ProfilAlumni(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	id := c.Params("id")
+	alumni, err := s.alumniRepo.GetByID(ctx, id)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if alumni == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
+	}
+	riwayat, err := s.pekerjaanRepo.GetByAlumniID(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	status, err := s.namaTaksonomi(ctx, model.JenisStatusPekerjaan)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	industri, err := s.namaTaksonomi(ctx, model.JenisBidangIndustri)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+
+	l := newLaporanPDF("Profil Alumni\n" + alumni.Nama)
+	l.subjudul("Data Diri")
+	l.keterangan([][2]string{
+		{"NIM", alumni.NIM},
+		{"Nama", alumni.Nama},
+		{"Jurusan", alumni.Jurusan},
+		{"Angkatan", strconv.Itoa(alumni.Angkatan)},
+		{"Tahun Lulus", strconv.Itoa(alumni.TahunLulus)},
+		{"Email", alumni.Email},
+		{"No. Telepon", alumni.NoTelepon},
+		{"Alamat", alumni.Alamat},
+	})
+
+	l.subjudul("Riwayat Pekerjaan")
+	rows := make([][]string, 0, len(riwayat))
+	for _, p := range riwayat {
+		selesai := "Sekarang"
+		if p.TanggalSelesaiKerja != nil {
+			selesai = tanggalIndonesia(*p.TanggalSelesaiKerja)
+		}
+		rows = append(rows, []string{
+			tanggalIndonesia(p.TanggalMulaiKerja) + " - " + selesai,
+			p.NamaPerusahaan,
+			p.PosisiJabatan,
+			istilah(industri, p.BidangIndustri),
+			p.LokasiKerja,
+			istilah(status, p.StatusPekerjaan),
+		})
+	}
+	l.tabel([]string{"Periode", "Perusahaan", "Posisi", "Bidang Industri", "Lokasi", "Status"},
+		[]float64{40, 35, 30, 27, 25, 23}, rows)
+
+	if len(riwayat) > 0 {
+		l.subjudul("Gaji")
+		gaji := make([][]string, 0, len(riwayat))
+		for _, p := range riwayat {
+			gaji = append(gaji, []string{p.NamaPerusahaan, p.PosisiJabatan, formatGaji(p.Gaji)})
+		}
+		l.tabel([]string{"Perusahaan", "Posisi", "Gaji"}, []float64{60, 50, 70}, gaji)
+	}
+
+	return s.simpan(c, l, fmt.Sprintf("profil-alumni-%s.pdf", alumni.NIM), &alumni.ID)
+}
+
+// RingkasanAngkatan membuat PDF ringkasan tracer study satu angkatan,
+// berisi tabel yang sama dengan endpoint /api/stats
+func (s *ReportService) RingkasanAngkatan(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	angkatan, err := strconv.Atoi(c.Params("angkatan"))
+	if err != nil || angkatan <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Angkatan tidak valid"})
+	}
+	f := model.AlumniFilter{Angkatan: angkatan}
+
+	total, err := s.statsRepo.GetTingkatKerja(ctx, "", f)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	if len(total) == 0 || total[0].TotalAlumni == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Tidak ada alumni pada angkatan ini"})
+	}
+	hitungRate(total)
+
+	tingkat, err := s.statsRepo.GetTingkatKerja(ctx, "jurusan", f)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	hitungRate(tingkat)
+
+	tunggu, err := s.statsRepo.GetWaktuTunggu(ctx, "jurusan", f)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	gaji, err := s.statsRepo.GetGajiByKelompok(ctx, "jurusan", "IDR", f)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+
+	distribusi := map[string][]model.Distribusi{}
+	for _, field := range []string{"nama_perusahaan", "bidang_industri", "lokasi_kerja"} {
+		distribusi[field], err = s.statsRepo.GetDistribusi(ctx, field, f, 10, false)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+		}
+	}
+	industri, err := s.namaTaksonomi(ctx, model.JenisBidangIndustri)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+
+	persen := func(v float64) string { return strconv.FormatFloat(v*100, 'f', 1, 64) + "%" }
+	angka := func(v float64) string { return formatRibuan(int64(v)) }
+
+	l := newLaporanPDF(fmt.Sprintf("Ringkasan Tracer Study\nAngkatan %d", angkatan))
+	l.keterangan([][2]string{
+		{"Jumlah Alumni", strconv.Itoa(total[0].TotalAlumni)},
+		{"Pernah Bekerja", fmt.Sprintf("%d (%s)", total[0].PernahBekerja, persen(total[0].Rate))},
+		{"Bekerja Saat Ini", fmt.Sprintf("%d (%s)", total[0].BekerjaSaatIni, persen(total[0].RateSaatIni))},
+	})
+
+	l.subjudul("Tingkat Kerja per Jurusan")
+	rows := [][]string{}
+	for _, t := range tingkat {
+		rows = append(rows, []string{fmt.Sprint(t.Kelompok), strconv.Itoa(t.TotalAlumni),
+			strconv.Itoa(t.PernahBekerja), persen(t.Rate), strconv.Itoa(t.BekerjaSaatIni), persen(t.RateSaatIni)})
+	}
+	l.tabel([]string{"Jurusan", "Alumni", "Pernah Kerja", "%", "Kerja Saat Ini", "%"},
+		[]float64{60, 20, 25, 20, 35, 20}, rows)
+
+	l.subjudul("Waktu Tunggu Pekerjaan Pertama per Jurusan")
+	rows = [][]string{}
+	for _, w := range ringkasWaktuTunggu(tunggu) {
+		rows = append(rows, []string{fmt.Sprint(w.Kelompok), strconv.Itoa(w.Jumlah),
+			strconv.FormatFloat(w.MedianBulan, 'f', 1, 64), strconv.FormatFloat(w.RataRata, 'f', 1, 64)})
+	}
+	l.tabel([]string{"Jurusan", "Alumni", "Median (bulan)", "Rata-rata (bulan)"}, []float64{80, 25, 37.5, 37.5}, rows)
+	l.catatan("Bulan kelulusan diasumsikan Juli pada tahun lulus.")
+
+	l.subjudul("Gaji Bulanan (IDR) per Jurusan")
+	rows = [][]string{}
+	for _, g := range ringkasGaji(gaji, []float64{25, 75}) {
+		rows = append(rows, []string{fmt.Sprint(g.Kelompok), strconv.Itoa(g.Jumlah),
+			angka(g.Persentil["p25"]), angka(g.Median), angka(g.Persentil["p75"])})
+	}
+	l.tabel([]string{"Jurusan", "Data", "P25", "Median", "P75"}, []float64{60, 20, 33, 34, 33}, rows)
+
+	for _, d := range []struct {
+		field, judul string
+		nama         map[string]string
+	}{
+		{"nama_perusahaan", "Perusahaan dengan Alumni Terbanyak", nil},
+		{"bidang_industri", "Distribusi Bidang Industri", industri},
+		{"lokasi_kerja", "Distribusi Lokasi Kerja", nil},
+	} {
+		l.subjudul(d.judul)
+		rows = [][]string{}
+		for _, x := range distribusi[d.field] {
+			rows = append(rows, []string{istilah(d.nama, x.Nama), strconv.Itoa(x.JumlahAlumni), strconv.Itoa(x.JumlahPekerjaan)})
+		}
+		l.tabel([]string{"Nama", "Alumni", "Pekerjaan"}, []float64{110, 35, 35}, rows)
+	}
+
+	return s.simpan(c, l, fmt.Sprintf("ringkasan-angkatan-%d.pdf", angkatan), nil)
+}
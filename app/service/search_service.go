@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Batas jumlah hasil yang digabung dari tiap koleksi (page * limit)
+const maxSearchWindow = 500
+
+// SearchService menyediakan pencarian gabungan alumni dan pekerjaan di /api/search
+type SearchService struct {
+	repo          repository.ISearchRepository
+	taksonomiRepo repository.ITaksonomiRepository
+}
+
+func NewSearchService(repo repository.ISearchRepository, taksonomiRepo repository.ITaksonomiRepository) *SearchService {
+	return &SearchService{repo: repo, taksonomiRepo: taksonomiRepo}
+}
+
+// EnsureIndexes membuat text index yang dibutuhkan pencarian
+func (s *SearchService) EnsureIndexes(ctx context.Context) error {
+	return s.repo.EnsureIndexes(ctx)
+}
+
+var frasaRe = regexp.MustCompile(`"([^"]+)"|(\S+)`)
+
+// kataKunci memecah q menjadi kata / frasa positif untuk highlight.
+// Kata berawalan '-' adalah pengecualian pada $text sehingga tidak ditandai.
+func kataKunci(q string) []string {
+	var terms []string
+	for _, m := range frasaRe.FindAllStringSubmatch(q, -1) {
+		t := m[1]
+		if t == "" {
+			t = m[2]
+		}
+		if strings.HasPrefix(t, "-") {
+			continue
+		}
+		if t = strings.Trim(t, `"`); t != "" {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// highlightRe membentuk regex kata utuh untuk semua kata kunci
+func highlightRe(terms []string) *regexp.Regexp {
+	if len(terms) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	// Frasa lebih panjang didahulukan agar tidak terpotong oleh kata di dalamnya
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// highlight mengembalikan field yang cocok dengan bagian cocoknya dibungkus <em>
+func highlight(re *regexp.Regexp, fields map[string]string) map[string]string {
+	if re == nil {
+		return nil
+	}
+	out := map[string]string{}
+	for name, text := range fields {
+		idx := re.FindAllStringIndex(text, -1)
+		if len(idx) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range idx {
+			b.WriteString(html.EscapeString(text[last:m[0]]))
+			b.WriteString("<em>" + html.EscapeString(text[m[0]:m[1]]) + "</em>")
+			last = m[1]
+		}
+		b.WriteString(html.EscapeString(text[last:]))
+		out[name] = b.String()
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// perluasQuery menambahkan kode taksonomi jika q cocok dengan nama / alias bidang industri,
+// karena pekerjaan menyimpan kode (misalnya "teknologi_informasi")
+func (s *SearchService) perluasQuery(ctx context.Context, q string) (string, []string) {
+	t, err := s.taksonomiRepo.Resolve(ctx, model.JenisBidangIndustri, normalizeIstilah(q))
+	if err != nil || t == nil {
+		return q, nil
+	}
+	return q + " " + t.Kode, []string{t.Kode}
+}
+
+// Search - Query: q (wajib), type=alumni|pekerjaan (opsional), page, limit
+func (s *SearchService) Search(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Parameter q wajib diisi"})
+	}
+	tipe := c.Query("type")
+	if tipe != "" && tipe != model.SearchAlumni && tipe != model.SearchPekerjaan {
+		return c.Status(400).JSON(fiber.Map{"error": "type harus alumni atau pekerjaan"})
+	}
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	// Dicek sebelum dikalikan agar page yang sangat besar tidak overflow
+	if page > maxSearchWindow/limit {
+		return c.Status(400).JSON(fiber.Map{"error": "Halaman terlalu jauh, persempit kata kunci"})
+	}
+	window := page * limit
+
+	search, extra := s.perluasQuery(ctx, q)
+	re := highlightRe(append(kataKunci(q), extra...))
+
+	// Koleksi yang tidak diminta tetap dihitung untuk facet, tanpa mengambil dokumen
+	alumniLimit, pekerjaanLimit := window, window
+	switch tipe {
+	case model.SearchAlumni:
+		pekerjaanLimit = 0
+	case model.SearchPekerjaan:
+		alumniLimit = 0
+	}
+
+	alumni, totalAlumni, err := s.repo.SearchAlumni(ctx, search, alumniLimit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencari data", "detail": err.Error()})
+	}
+	pekerjaan, totalPekerjaan, err := s.repo.SearchPekerjaan(ctx, search, pekerjaanLimit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencari data", "detail": err.Error()})
+	}
+
+	hits := make([]model.SearchHit, 0, len(alumni)+len(pekerjaan))
+	for _, a := range alumni {
+		hits = append(hits, model.SearchHit{
+			Type:  model.SearchAlumni,
+			ID:    a.ID.Hex(),
+			Score: a.Score,
+			Judul: a.Nama,
+			Data:  a.Alumni,
+			Highlights: highlight(re, map[string]string{
+				"nama": a.Nama, "nim": a.NIM, "jurusan": a.Jurusan,
+			}),
+		})
+	}
+	for _, p := range pekerjaan {
+		hits = append(hits, model.SearchHit{
+			Type:  model.SearchPekerjaan,
+			ID:    p.ID.Hex(),
+			Score: p.Score,
+			Judul: p.PosisiJabatan + " - " + p.NamaPerusahaan,
+			Data:  p,
+			Highlights: highlight(re, map[string]string{
+				"nama_perusahaan": p.NamaPerusahaan, "posisi_jabatan": p.PosisiJabatan, "bidang_industri": p.BidangIndustri,
+			}),
+		})
+	}
+
+	// Skor kedua koleksi berasal dari bobot text index masing-masing
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+
+	start := (page - 1) * limit
+	if start > len(hits) {
+		start = len(hits)
+	}
+	end := start + limit
+	if end > len(hits) || end < start {
+		end = len(hits)
+	}
+
+	total := totalAlumni + totalPekerjaan
+	switch tipe {
+	case model.SearchAlumni:
+		total = totalAlumni
+	case model.SearchPekerjaan:
+		total = totalPekerjaan
+	}
+
+	return c.JSON(fiber.Map{
+		"q":     q,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+		"facets": fiber.Map{
+			model.SearchAlumni:    totalAlumni,
+			model.SearchPekerjaan: totalPekerjaan,
+		},
+		"data": hits[start:end],
+	})
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeSearchRepo mencatat limit yang diminta; method lain panic
+type fakeSearchRepo struct {
+	repository.ISearchRepository
+
+	limits []int
+}
+
+func (r *fakeSearchRepo) SearchAlumni(ctx context.Context, q string, limit int) ([]model.AlumniSearchResult, int64, error) {
+	r.limits = append(r.limits, limit)
+	return nil, 0, nil
+}
+
+func (r *fakeSearchRepo) SearchPekerjaan(ctx context.Context, q string, limit int) ([]model.PekerjaanSearchResult, int64, error) {
+	r.limits = append(r.limits, limit)
+	return nil, 0, nil
+}
+
+type fakeTaksonomiRepo struct {
+	repository.ITaksonomiRepository
+}
+
+func (fakeTaksonomiRepo) Resolve(ctx context.Context, jenis, istilah string) (*model.Taksonomi, error) {
+	return nil, nil
+}
+
+func TestSearchHalamanTerlaluJauh(t *testing.T) {
+	for _, url := range []string{
+		"/search?q=go&page=26&limit=20",
+		// page*limit overflow menjadi negatif jika dikalikan lebih dulu
+		"/search?q=go&page=92233720368547759&limit=100",
+	} {
+		repo := &fakeSearchRepo{}
+		app := fiber.New()
+		app.Get("/search", NewSearchService(repo, fakeTaksonomiRepo{}).Search)
+
+		var body fiber.Map
+		if status := getJSON(t, app, url, &body); status != 400 {
+			t.Errorf("%s: status = %d, want 400", url, status)
+		}
+		if len(repo.limits) != 0 {
+			t.Errorf("%s: repo tidak boleh dipanggil, got %v", url, repo.limits)
+		}
+	}
+}
+
+func TestSearchHalamanTerakhir(t *testing.T) {
+	repo := &fakeSearchRepo{}
+	app := fiber.New()
+	app.Get("/search", NewSearchService(repo, fakeTaksonomiRepo{}).Search)
+
+	var body fiber.Map
+	if status := getJSON(t, app, "/search?q=go&page=25&limit=20", &body); status != 200 {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if len(repo.limits) != 2 || repo.limits[0] != 500 || repo.limits[1] != 500 {
+		t.Errorf("limit repo = %v, want [500 500]", repo.limits)
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// queryParams adalah sumber parameter statistik: *fiber.Ctx saat request biasa,
+// atau StatsParams saat cache direfresh di background.
+type queryParams interface {
+	Query(key string, defaultValue ...string) string
+	QueryInt(key string, defaultValue ...int) int
+	QueryBool(key string, defaultValue ...bool) bool
+}
+
+// StatsParams adalah parameter query yang tersimpan bersama cache statistik
+type StatsParams map[string]string
+
+func (p StatsParams) Query(key string, defaultValue ...string) string {
+	if v, ok := p[key]; ok && v != "" {
+		return v
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return ""
+}
+
+func (p StatsParams) QueryInt(key string, defaultValue ...int) int {
+	if v, err := strconv.Atoi(p[key]); err == nil {
+		return v
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return 0
+}
+
+func (p StatsParams) QueryBool(key string, defaultValue ...bool) bool {
+	if v, err := strconv.ParseBool(p[key]); err == nil {
+		return v
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return false
+}
+
+// statsCompute menghitung satu statistik menjadi body response
+type statsCompute func(ctx context.Context, q queryParams) (fiber.Map, error)
+
+// statsStat adalah statistik terdaftar beserta parameter query yang dipakainya.
+// Hanya parameter ini yang masuk key cache, sehingga query lain (misalnya
+// ?_=timestamp) tidak membuat entri baru.
+type statsStat struct {
+	params  []string
+	compute statsCompute
+}
+
+// pick mengambil parameter yang dipakai statistik dari q
+func (st statsStat) pick(q queryParams) StatsParams {
+	params := StatsParams{}
+	for _, k := range st.params {
+		if v := q.Query(k); v != "" {
+			// Disalin karena string dari fiber.Ctx hanya valid selama request
+			params[k] = strings.Clone(v)
+		}
+	}
+	return params
+}
+
+// statsParamError menandai parameter query yang tidak valid (response 400)
+type statsParamError struct {
+	msg string
+}
+
+func (e statsParamError) Error() string { return e.msg }
+
+// StatsCacheService menyimpan hasil agregasi statistik di koleksi stats_cache.
+// Request membaca dari cache; worker background menghitung ulang semua entri
+// secara berkala, dan admin bisa memaksa refresh. Entri yang tidak dibaca
+// selama ttl dihapus oleh TTL index, dan semua entri ditandai kedaluwarsa
+// saat data alumni / pekerjaan berubah.
+type StatsCacheService struct {
+	repo     repository.IStatsCacheRepository
+	interval time.Duration
+	maxAge   time.Duration
+	ttl      time.Duration
+
+	mu    sync.RWMutex
+	stats map[string]statsStat
+}
+
+func NewStatsCacheService(repo repository.IStatsCacheRepository, interval, ttl time.Duration) *StatsCacheService {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &StatsCacheService{
+		repo:     repo,
+		interval: interval,
+		// Entri yang terlewat dua kali refresh dihitung ulang saat dibaca
+		maxAge: 2 * interval,
+		ttl:    ttl,
+		stats:  map[string]statsStat{},
+	}
+}
+
+// EnsureIndexes membuat TTL index read_at pada stats_cache
+func (s *StatsCacheService) EnsureIndexes(ctx context.Context) error {
+	return s.repo.EnsureIndexes(ctx, s.ttl)
+}
+
+// Register mendaftarkan fungsi hitung untuk satu nama statistik beserta
+// parameter query yang dipakainya
+func (s *StatsCacheService) Register(name string, params []string, compute statsCompute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats[name] = statsStat{params: params, compute: compute}
+}
+
+func (s *StatsCacheService) stat(name string) (statsStat, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st, ok := s.stats[name]
+	return st, ok
+}
+
+// Invalidate menandai semua entri cache kedaluwarsa sehingga request berikutnya
+// menghitung ulang. Dipanggil setelah data alumni / pekerjaan berubah.
+func (s *StatsCacheService) Invalidate() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.repo.Expire(ctx); err != nil {
+		log.Println("Gagal menandai stats_cache kedaluwarsa:", err)
+	}
+}
+
+// cacheKey membentuk key dari nama dan parameter yang sudah diurutkan
+func cacheKey(name string, params StatsParams) string {
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	return name + "?" + values.Encode()
+}
+
+// Serve mengirim statistik dari cache, atau menghitung dan menyimpannya jika
+// belum ada / sudah kedaluwarsa
+func (s *StatsCacheService) Serve(c *fiber.Ctx, name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	st, ok := s.stat(name)
+	if !ok {
+		return c.Status(500).JSON(fiber.Map{"error": "Statistik " + name + " tidak terdaftar"})
+	}
+	params := st.pick(c)
+
+	now := time.Now()
+	entry, err := s.repo.Get(ctx, cacheKey(name, params))
+	if err != nil {
+		log.Println("Gagal membaca stats_cache:", err)
+	}
+	if entry != nil && now.Sub(entry.ReadAt) > s.interval {
+		// read_at cukup diperbarui sesekali; dipakai TTL index untuk membuang entri yang tidak dibaca
+		if err := s.repo.Touch(ctx, entry.Key, now); err != nil {
+			log.Println("Gagal memperbarui stats_cache:", err)
+		}
+	}
+	if entry == nil || now.Sub(entry.GeneratedAt) > s.maxAge {
+		entry, err = s.refresh(ctx, name, params, now)
+		var paramErr statsParamError
+		if errors.As(err, &paramErr) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.SendString(entry.Data)
+}
+
+// refresh menghitung ulang satu statistik dan menyimpannya ke cache.
+// readAt adalah waktu baca terakhir; refresh background memakai nilai lama.
+func (s *StatsCacheService) refresh(ctx context.Context, name string, params StatsParams, readAt time.Time) (*model.StatsCache, error) {
+	st, ok := s.stat(name)
+	if !ok {
+		return nil, errors.New("statistik " + name + " tidak terdaftar")
+	}
+
+	data, err := st.compute(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	data["generated_at"] = now
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &model.StatsCache{
+		Key:         cacheKey(name, params),
+		Name:        name,
+		Params:      params,
+		Data:        string(body),
+		GeneratedAt: now,
+		ReadAt:      readAt,
+	}
+	if err := s.repo.Save(ctx, entry); err != nil {
+		// Hasil tetap dikirim walaupun cache gagal disimpan
+		log.Println("Gagal menyimpan stats_cache:", err)
+	}
+	return entry, nil
+}
+
+// RefreshAll menghitung ulang semua entri cache (atau satu nama statistik saja)
+func (s *StatsCacheService) RefreshAll(ctx context.Context, name string) (int, error) {
+	entries, err := s.repo.GetAll(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, e := range entries {
+		st, ok := s.stat(e.Name)
+		if !ok || cacheKey(e.Name, st.pick(StatsParams(e.Params))) != e.Key {
+			// Statistik sudah tidak ada, atau key lama yang memuat parameter di luar daftar
+			if err := s.repo.Delete(ctx, e.Key); err != nil {
+				log.Printf("Gagal menghapus stats_cache %s: %v", e.Key, err)
+			}
+			continue
+		}
+		if _, err := s.refresh(ctx, e.Name, e.Params, e.ReadAt); err != nil {
+			log.Printf("Gagal refresh statistik %s: %v", e.Key, err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// Start menjalankan refresh terjadwal di background sampai ctx dibatalkan
+func (s *StatsCacheService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+				n, err := s.RefreshAll(runCtx, "")
+				cancel()
+				if err != nil {
+					log.Println("Refresh stats_cache gagal:", err)
+					continue
+				}
+				log.Printf("Refresh stats_cache: %d entri diperbarui", n)
+			}
+		}
+	}()
+}
+
+// ------------------- Handler Admin -------------------
+
+// ForceRefresh menghitung ulang cache statistik sekarang. Query: name (opsional)
+func (s *StatsCacheService) ForceRefresh(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	n, err := s.RefreshAll(ctx, c.Query("name"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal refresh statistik", "detail": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Statistik berhasil direfresh", "refreshed": n, "generated_at": time.Now()})
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"praktikummongo/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeStatsCacheRepo menyimpan cache di memori
+type fakeStatsCacheRepo struct {
+	mu      sync.Mutex
+	entries map[string]model.StatsCache
+}
+
+func newFakeStatsCacheRepo() *fakeStatsCacheRepo {
+	return &fakeStatsCacheRepo{entries: map[string]model.StatsCache{}}
+}
+
+func (r *fakeStatsCacheRepo) Get(ctx context.Context, key string) (*model.StatsCache, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+func (r *fakeStatsCacheRepo) Save(ctx context.Context, entry *model.StatsCache) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := *entry
+	if old, ok := r.entries[e.Key]; ok && old.ReadAt.After(e.ReadAt) {
+		e.ReadAt = old.ReadAt
+	}
+	r.entries[e.Key] = e
+	return nil
+}
+
+func (r *fakeStatsCacheRepo) GetAll(ctx context.Context, name string) ([]model.StatsCache, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []model.StatsCache{}
+	for _, e := range r.entries {
+		if name == "" || e.Name == name {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeStatsCacheRepo) Touch(ctx context.Context, key string, readAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.entries[key]; ok && readAt.After(e.ReadAt) {
+		e.ReadAt = readAt
+		r.entries[key] = e
+	}
+	return nil
+}
+
+func (r *fakeStatsCacheRepo) Expire(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, e := range r.entries {
+		e.GeneratedAt = time.Time{}
+		r.entries[k] = e
+	}
+	return nil
+}
+
+func (r *fakeStatsCacheRepo) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, key)
+	return nil
+}
+
+func (r *fakeStatsCacheRepo) EnsureIndexes(ctx context.Context, ttl time.Duration) error {
+	return nil
+}
+
+// countingStat mendaftarkan statistik "test" yang mencatat berapa kali dihitung
+func countingStat(params ...string) (*StatsCacheService, *fakeStatsCacheRepo, *fiber.App, *int) {
+	repo := newFakeStatsCacheRepo()
+	cache := NewStatsCacheService(repo, 0, 0)
+	n := 0
+	cache.Register("test", params, func(ctx context.Context, q queryParams) (fiber.Map, error) {
+		n++
+		return fiber.Map{"group_by": q.Query("group_by")}, nil
+	})
+	app := fiber.New()
+	app.Get("/stats", func(c *fiber.Ctx) error { return cache.Serve(c, "test") })
+	return cache, repo, app, &n
+}
+
+func TestStatsCacheKeyHanyaParameterTerdaftar(t *testing.T) {
+	_, repo, app, n := countingStat("group_by")
+
+	for _, url := range []string{
+		"/stats?group_by=jurusan",
+		"/stats?group_by=jurusan&_=1700000000",
+		"/stats?foo=bar&group_by=jurusan",
+	} {
+		if status := getJSON(t, app, url, nil); status != 200 {
+			t.Fatalf("%s: status = %d", url, status)
+		}
+	}
+	if *n != 1 {
+		t.Errorf("dihitung %d kali, want 1 (parameter lain tidak boleh membuat key baru)", *n)
+	}
+	if len(repo.entries) != 1 {
+		t.Errorf("entri cache = %d, want 1", len(repo.entries))
+	}
+
+	getJSON(t, app, "/stats?group_by=angkatan", nil)
+	if *n != 2 || len(repo.entries) != 2 {
+		t.Errorf("parameter terdaftar yang berbeda harus punya entri sendiri: n=%d entri=%d", *n, len(repo.entries))
+	}
+}
+
+func TestStatsCacheInvalidate(t *testing.T) {
+	cache, _, app, n := countingStat("group_by")
+
+	getJSON(t, app, "/stats?group_by=jurusan", nil)
+	getJSON(t, app, "/stats?group_by=jurusan", nil)
+	if *n != 1 {
+		t.Fatalf("dihitung %d kali sebelum invalidate, want 1", *n)
+	}
+
+	cache.Invalidate()
+	getJSON(t, app, "/stats?group_by=jurusan", nil)
+	if *n != 2 {
+		t.Errorf("dihitung %d kali setelah invalidate, want 2", *n)
+	}
+}
+
+func TestStatsCacheRefreshAllBuangKeyLama(t *testing.T) {
+	cache, repo, _, n := countingStat("group_by")
+
+	// Entri dari versi lama yang key-nya memuat parameter di luar daftar
+	old := model.StatsCache{Key: "test?_=1&group_by=jurusan", Name: "test", Params: map[string]string{"_": "1", "group_by": "jurusan"}}
+	repo.entries[old.Key] = old
+
+	refreshed, err := cache.RefreshAll(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed != 0 || *n != 0 {
+		t.Errorf("refreshed=%d dihitung=%d, want 0", refreshed, *n)
+	}
+	if _, ok := repo.entries[old.Key]; ok {
+		t.Error("entri dengan key lama harus dihapus")
+	}
+}
+
+func TestStatsCacheReadAt(t *testing.T) {
+	cache, repo, app, _ := countingStat("group_by")
+
+	getJSON(t, app, "/stats?group_by=jurusan", nil)
+	key := cacheKey("test", StatsParams{"group_by": "jurusan"})
+	readAt := repo.entries[key].ReadAt
+	if readAt.IsZero() {
+		t.Fatal("read_at harus diisi saat entri dibuat dari request")
+	}
+
+	// Refresh background tidak boleh dihitung sebagai baca
+	if _, err := cache.RefreshAll(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.entries[key].ReadAt; !got.Equal(readAt) {
+		t.Errorf("read_at berubah menjadi %v setelah refresh background, want %v", got, readAt)
+	}
+
+	// Entri yang lama tidak dibaca mendapat read_at baru saat dibaca lagi
+	e := repo.entries[key]
+	e.ReadAt = time.Now().Add(-48 * time.Hour)
+	repo.entries[key] = e
+	resp, err := app.Test(httptest.NewRequest("GET", "/stats?group_by=jurusan", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if time.Since(repo.entries[key].ReadAt) > time.Minute {
+		t.Errorf("read_at = %v, want diperbarui saat dibaca", repo.entries[key].ReadAt)
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatsService menyediakan endpoint statistik tracer study di /api/stats.
+// Semua hasil disajikan lewat StatsCacheService (koleksi stats_cache).
+type StatsService struct {
+	repo  repository.IStatsRepository
+	cache *StatsCacheService
+}
+
+func NewStatsService(repo repository.IStatsRepository, cache *StatsCacheService) *StatsService {
+	s := &StatsService{repo: repo, cache: cache}
+	cache.Register("stats.gaji", statsParams("group_by", "currency", "p"), s.computeGaji)
+	cache.Register("stats.tingkat_kerja", statsParams("group_by"), s.computeTingkatKerja)
+	cache.Register("stats.waktu_tunggu", statsParams("group_by"), s.computeWaktuTunggu)
+	cache.Register("stats.perusahaan", statsParams("limit", "saat_ini"), s.computeDistribusi("nama_perusahaan", 10))
+	cache.Register("stats.industri", statsParams("limit", "saat_ini"), s.computeDistribusi("bidang_industri", 0))
+	cache.Register("stats.lokasi", statsParams("limit", "saat_ini"), s.computeDistribusi("lokasi_kerja", 0))
+	return s
+}
+
+// statsParams adalah parameter statistik ditambah filter alumni
+func statsParams(params ...string) []string {
+	return append(params, alumniFilterParams...)
+}
+
+// groupByError mengubah ErrGroupByTidakDidukung menjadi error parameter (400)
+func groupByError(err error) error {
+	if errors.Is(err, repository.ErrGroupByTidakDidukung) {
+		return statsParamError{msg: err.Error()}
+	}
+	return err
+}
+
+// ------------------- Gaji -------------------
+
+// GetStatistikGaji mengembalikan median dan persentil gaji bulanan per kelompok.
+// Query: group_by=jurusan|angkatan|bidang_industri, currency=IDR, p=25,50,75,90,
+// ditambah filter alumni (search, jurusan, angkatan, tahun_lulus)
+func (s *StatsService) GetStatistikGaji(c *fiber.Ctx) error {
+	return s.cache.Serve(c, "stats.gaji")
+}
+
+func (s *StatsService) computeGaji(ctx context.Context, q queryParams) (fiber.Map, error) {
+	groupBy := q.Query("group_by", "jurusan")
+	currency := strings.ToUpper(q.Query("currency", "IDR"))
+
+	var ps []float64
+	for _, raw := range strings.Split(q.Query("p", "25,50,75,90"), ",") {
+		p, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, statsParamError{msg: "Persentil harus angka 0-100: " + raw}
+		}
+		ps = append(ps, p)
+	}
+
+	groups, err := s.repo.GetGajiByKelompok(ctx, groupBy, currency, parseAlumniFilter(q))
+	if err != nil {
+		return nil, groupByError(err)
+	}
+
+	results := ringkasGaji(groups, ps)
+
+	return fiber.Map{
+		"group_by": groupBy,
+		"currency": currency,
+		"period":   "bulan",
+		"data":     results,
+	}, nil
+}
+
+// ------------------- Tingkat Kerja -------------------
+
+// GetTingkatKerja mengembalikan persentase alumni yang pernah / sedang bekerja.
+// Query: group_by=angkatan|jurusan|tahun_lulus (kosong = total), filter alumni
+func (s *StatsService) GetTingkatKerja(c *fiber.Ctx) error {
+	return s.cache.Serve(c, "stats.tingkat_kerja")
+}
+
+func (s *StatsService) computeTingkatKerja(ctx context.Context, q queryParams) (fiber.Map, error) {
+	groupBy := q.Query("group_by", "angkatan")
+	results, err := s.repo.GetTingkatKerja(ctx, groupBy, parseAlumniFilter(q))
+	if err != nil {
+		return nil, groupByError(err)
+	}
+
+	hitungRate(results)
+
+	return fiber.Map{"group_by": groupBy, "data": results}, nil
+}
+
+// ------------------- Waktu Tunggu -------------------
+
+// GetWaktuTunggu mengembalikan median waktu (bulan) dari TahunLulus ke pekerjaan pertama.
+// Query: group_by=angkatan|jurusan|tahun_lulus (kosong = total), filter alumni
+func (s *StatsService) GetWaktuTunggu(c *fiber.Ctx) error {
+	return s.cache.Serve(c, "stats.waktu_tunggu")
+}
+
+func (s *StatsService) computeWaktuTunggu(ctx context.Context, q queryParams) (fiber.Map, error) {
+	groupBy := q.Query("group_by", "")
+	groups, err := s.repo.GetWaktuTunggu(ctx, groupBy, parseAlumniFilter(q))
+	if err != nil {
+		return nil, groupByError(err)
+	}
+
+	results := ringkasWaktuTunggu(groups)
+
+	return fiber.Map{"group_by": groupBy, "data": results}, nil
+}
+
+// ------------------- Distribusi -------------------
+
+// GetTopPerusahaan mengembalikan perusahaan dengan alumni terbanyak
+func (s *StatsService) GetTopPerusahaan(c *fiber.Ctx) error {
+	return s.cache.Serve(c, "stats.perusahaan")
+}
+
+// GetDistribusiIndustri mengembalikan sebaran pekerjaan per bidang industri
+func (s *StatsService) GetDistribusiIndustri(c *fiber.Ctx) error {
+	return s.cache.Serve(c, "stats.industri")
+}
+
+// GetDistribusiLokasi mengembalikan sebaran pekerjaan per lokasi kerja
+func (s *StatsService) GetDistribusiLokasi(c *fiber.Ctx) error {
+	return s.cache.Serve(c, "stats.lokasi")
+}
+
+// computeDistribusi menjalankan agregasi distribusi. Query: limit, saat_ini=true, filter alumni
+func (s *StatsService) computeDistribusi(field string, defaultLimit int) statsCompute {
+	return func(ctx context.Context, q queryParams) (fiber.Map, error) {
+		limit := q.QueryInt("limit", defaultLimit)
+		saatIni := q.QueryBool("saat_ini", false)
+
+		results, err := s.repo.GetDistribusi(ctx, field, parseAlumniFilter(q), limit, saatIni)
+		if err != nil {
+			return nil, groupByError(err)
+		}
+		return fiber.Map{"field": field, "saat_ini": saatIni, "data": results}, nil
+	}
+}
+
+// ringkasGaji menghitung min, max, median, dan persentil ps untuk tiap kelompok gaji
+func ringkasGaji(groups []model.GajiKelompok, ps []float64) []model.StatistikGaji {
+	results := make([]model.StatistikGaji, 0, len(groups))
+	for _, g := range groups {
+		if len(g.Nilai) == 0 {
+			continue
+		}
+		sort.Float64s(g.Nilai)
+
+		stat := model.StatistikGaji{
+			Kelompok:  g.Kelompok,
+			Jumlah:    len(g.Nilai),
+			Min:       g.Nilai[0],
+			Max:       g.Nilai[len(g.Nilai)-1],
+			Median:    persentil(g.Nilai, 50),
+			Persentil: make(map[string]float64, len(ps)),
+		}
+		for _, p := range ps {
+			stat.Persentil["p"+strconv.FormatFloat(p, 'f', -1, 64)] = persentil(g.Nilai, p)
+		}
+		results = append(results, stat)
+	}
+	return results
+}
+
+// hitungRate mengisi Rate dan RateSaatIni dari jumlah alumni
+func hitungRate(results []model.TingkatKerja) {
+	for i := range results {
+		if results[i].TotalAlumni > 0 {
+			total := float64(results[i].TotalAlumni)
+			results[i].Rate = float64(results[i].PernahBekerja) / total
+			results[i].RateSaatIni = float64(results[i].BekerjaSaatIni) / total
+		}
+	}
+}
+
+// ringkasWaktuTunggu menghitung median dan rata-rata waktu tunggu tiap kelompok
+func ringkasWaktuTunggu(groups []model.WaktuTungguKelompok) []model.StatistikWaktuTunggu {
+	results := make([]model.StatistikWaktuTunggu, 0, len(groups))
+	for _, g := range groups {
+		if len(g.Bulan) == 0 {
+			continue
+		}
+		sort.Float64s(g.Bulan)
+
+		sum := 0.0
+		for _, b := range g.Bulan {
+			sum += b
+		}
+		results = append(results, model.StatistikWaktuTunggu{
+			Kelompok:    g.Kelompok,
+			Jumlah:      len(g.Bulan),
+			MedianBulan: persentil(g.Bulan, 50),
+			RataRata:    sum / float64(len(g.Bulan)),
+		})
+	}
+	return results
+}
+
+// persentil menghitung persentil p (0-100) dari data terurut dengan interpolasi linear
+func persentil(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
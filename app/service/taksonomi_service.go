@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"praktikummongo/app/model"
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TaksonomiService mengelola kosakata terkontrol untuk status pekerjaan dan bidang industri
+type TaksonomiService struct {
+	repo          repository.ITaksonomiRepository
+	pekerjaanRepo repository.IPekerjaanRepository
+	cache         *StatsCacheService
+}
+
+func NewTaksonomiService(repo repository.ITaksonomiRepository, pekerjaanRepo repository.IPekerjaanRepository, cache *StatsCacheService) *TaksonomiService {
+	return &TaksonomiService{repo: repo, pekerjaanRepo: pekerjaanRepo, cache: cache}
+}
+
+var jenisTaksonomi = map[string]bool{
+	model.JenisStatusPekerjaan: true,
+	model.JenisBidangIndustri:  true,
+}
+
+// Kosakata awal yang diisi saat koleksi masih kosong
+var defaultTaksonomi = map[string][]model.Taksonomi{
+	model.JenisStatusPekerjaan: {
+		{Kode: "penuh_waktu", Nama: "Karyawan Penuh Waktu", Alias: []string{"full time", "full-time", "tetap", "karyawan tetap", "aktif"}},
+		{Kode: "kontrak", Nama: "Karyawan Kontrak", Alias: []string{"contract", "pkwt"}},
+		{Kode: "paruh_waktu", Nama: "Paruh Waktu", Alias: []string{"part time", "part-time"}},
+		{Kode: "magang", Nama: "Magang", Alias: []string{"internship", "intern"}},
+		{Kode: "wirausaha", Nama: "Wirausaha", Alias: []string{"wiraswasta", "entrepreneur", "usaha sendiri"}},
+		{Kode: "freelance", Nama: "Freelance", Alias: []string{"lepas", "pekerja lepas"}},
+		{Kode: "selesai", Nama: "Sudah Tidak Bekerja", Alias: []string{"resign", "berhenti", "tidak aktif"}},
+	},
+	model.JenisBidangIndustri: {
+		{Kode: "teknologi_informasi", Nama: "Teknologi Informasi", Alias: []string{"it", "ti", "information technology", "software", "teknologi"}},
+		{Kode: "telekomunikasi", Nama: "Telekomunikasi", Alias: []string{"telco", "telecommunication"}},
+		{Kode: "keuangan", Nama: "Keuangan dan Perbankan", Alias: []string{"perbankan", "bank", "finance", "fintech"}},
+		{Kode: "pendidikan", Nama: "Pendidikan", Alias: []string{"education", "edukasi"}},
+		{Kode: "kesehatan", Nama: "Kesehatan", Alias: []string{"health", "healthcare", "medis"}},
+		{Kode: "manufaktur", Nama: "Manufaktur", Alias: []string{"manufacturing", "industri"}},
+		{Kode: "perdagangan", Nama: "Perdagangan dan Ritel", Alias: []string{"retail", "ritel", "e-commerce", "ecommerce"}},
+		{Kode: "pemerintahan", Nama: "Pemerintahan", Alias: []string{"government", "pns", "bumn"}},
+		{Kode: "konsultan", Nama: "Konsultan", Alias: []string{"consulting", "konsultasi"}},
+		{Kode: "lainnya", Nama: "Lainnya", Alias: []string{"other", "lain-lain"}},
+	},
+}
+
+// ErrIstilahTidakDikenal dikembalikan jika nilai tidak ada di kosakata
+type ErrIstilahTidakDikenal struct {
+	Jenis string
+	Value string
+}
+
+func (e ErrIstilahTidakDikenal) Error() string {
+	return fmt.Sprintf("%s '%s' tidak dikenal", e.Jenis, e.Value)
+}
+
+var nonKodeRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalizeIstilah menyeragamkan huruf dan spasi sebelum dicocokkan ke kosakata
+func normalizeIstilah(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// kodeIstilah membentuk kode dari nama, misalnya "Teknologi Informasi" -> "teknologi_informasi"
+func kodeIstilah(s string) string {
+	return strings.Trim(nonKodeRe.ReplaceAllString(strings.ToLower(s), "_"), "_")
+}
+
+// resolveTaksonomi mengubah nilai bebas menjadi kode baku. Nilai kosong dibiarkan kosong.
+func resolveTaksonomi(ctx context.Context, repo repository.ITaksonomiRepository, jenis, value string) (string, error) {
+	v := normalizeIstilah(value)
+	if v == "" {
+		return "", nil
+	}
+	t, err := repo.Resolve(ctx, jenis, v)
+	if err != nil {
+		return "", err
+	}
+	if t == nil {
+		return "", ErrIstilahTidakDikenal{Jenis: jenis, Value: value}
+	}
+	return t.Kode, nil
+}
+
+// taksonomiError menulis response untuk error dari resolveTaksonomi
+func taksonomiError(c *fiber.Ctx, err error) error {
+	var unknown ErrIstilahTidakDikenal
+	if errors.As(err, &unknown) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":  "Input tidak valid",
+			"detail": err.Error(),
+			"lihat":  "/api/taksonomi/" + unknown.Jenis,
+		})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "Gagal memvalidasi taksonomi", "detail": err.Error()})
+}
+
+// EnsureIndexes membuat unique index (jenis, kode)
+func (s *TaksonomiService) EnsureIndexes(ctx context.Context) error {
+	return s.repo.EnsureIndexes(ctx)
+}
+
+// EnsureDefaults mengisi kosakata awal untuk jenis yang masih kosong
+func (s *TaksonomiService) EnsureDefaults(ctx context.Context) error {
+	for jenis, list := range defaultTaksonomi {
+		n, err := s.repo.Count(ctx, jenis)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			continue
+		}
+		for _, t := range list {
+			t.Jenis = jenis
+			prepareIstilah(&t)
+			t.CreatedAt = time.Now()
+			t.UpdatedAt = t.CreatedAt
+			if _, err := s.repo.Create(ctx, &t); err != nil {
+				return err
+			}
+		}
+		log.Printf("Taksonomi %s diisi dengan %d istilah awal", jenis, len(list))
+	}
+	return nil
+}
+
+// prepareIstilah menormalkan kode dan alias; nama ikut dijadikan alias agar bisa dicocokkan
+func prepareIstilah(t *model.Taksonomi) {
+	if t.Kode == "" {
+		t.Kode = t.Nama
+	}
+	t.Kode = kodeIstilah(t.Kode)
+
+	seen := map[string]bool{}
+	alias := []string{}
+	for _, a := range append([]string{t.Nama}, t.Alias...) {
+		a = normalizeIstilah(a)
+		if a != "" && a != t.Kode && !seen[a] {
+			seen[a] = true
+			alias = append(alias, a)
+		}
+	}
+	t.Alias = alias
+}
+
+// checkBentrok memastikan kode / alias tidak sudah dipakai istilah lain dengan jenis sama
+func (s *TaksonomiService) checkBentrok(ctx context.Context, t *model.Taksonomi) error {
+	for _, v := range append([]string{t.Kode}, t.Alias...) {
+		other, err := s.repo.Resolve(ctx, t.Jenis, v)
+		if err != nil {
+			return err
+		}
+		if other != nil && other.ID != t.ID {
+			return fmt.Errorf("'%s' sudah dipakai oleh istilah %s", v, other.Kode)
+		}
+	}
+	return nil
+}
+
+// ------------------- Handler -------------------
+
+func (s *TaksonomiService) GetAll(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	jenis := c.Params("jenis")
+	if !jenisTaksonomi[jenis] {
+		return c.Status(404).JSON(fiber.Map{"error": "Jenis taksonomi tidak dikenal"})
+	}
+
+	list, err := s.repo.GetAll(ctx, jenis)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	return c.JSON(list)
+}
+
+func (s *TaksonomiService) Create(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	jenis := c.Params("jenis")
+	if !jenisTaksonomi[jenis] {
+		return c.Status(404).JSON(fiber.Map{"error": "Jenis taksonomi tidak dikenal"})
+	}
+
+	var t model.Taksonomi
+	if err := c.BodyParser(&t); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Input tidak valid", "detail": err.Error()})
+	}
+	if strings.TrimSpace(t.Nama) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Nama wajib diisi"})
+	}
+
+	t.ID = primitive.NilObjectID
+	t.Jenis = jenis
+	prepareIstilah(&t)
+	if err := s.checkBentrok(ctx, &t); err != nil {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	newData, err := s.repo.Create(ctx, &t)
+	if mongo.IsDuplicateKeyError(err) {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("'%s' sudah dipakai oleh istilah lain", t.Kode)})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan data", "detail": err.Error()})
+	}
+	return c.Status(201).JSON(newData)
+}
+
+func (s *TaksonomiService) Update(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id := c.Params("id")
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	if existing == nil || existing.Jenis != c.Params("jenis") {
+		return c.Status(404).JSON(fiber.Map{"error": "Istilah tidak ditemukan"})
+	}
+
+	var t model.Taksonomi
+	if err := c.BodyParser(&t); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Input tidak valid", "detail": err.Error()})
+	}
+	if strings.TrimSpace(t.Nama) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Nama wajib diisi"})
+	}
+
+	// Kode tetap; hanya nama dan alias yang boleh berubah
+	t.ID = existing.ID
+	t.Jenis = existing.Jenis
+	t.Kode = existing.Kode
+	prepareIstilah(&t)
+	if err := s.checkBentrok(ctx, &t); err != nil {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	t.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, id, &t); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memperbarui data", "detail": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Istilah berhasil diupdate"})
+}
+
+// Delete menghapus istilah. Istilah yang masih dipakai pekerjaan ditolak (409)
+// kecuali ?ganti=<kode> diisi: pekerjaan tersebut dipindah ke kode pengganti dulu.
+func (s *TaksonomiService) Delete(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	id := c.Params("id")
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+	if existing == nil || existing.Jenis != c.Params("jenis") {
+		return c.Status(404).JSON(fiber.Map{"error": "Istilah tidak ditemukan"})
+	}
+
+	var dipindah int64
+	if ganti := c.Query("ganti"); ganti != "" {
+		pengganti, err := s.repo.Resolve(ctx, existing.Jenis, normalizeIstilah(ganti))
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+		}
+		if pengganti == nil || pengganti.ID == existing.ID {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Istilah pengganti '%s' tidak valid", ganti)})
+		}
+		if dipindah, err = s.pekerjaanRepo.ReplaceTaksonomi(ctx, existing.Jenis, existing.Kode, pengganti.Kode); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memindahkan pekerjaan ke istilah pengganti", "detail": err.Error()})
+		}
+		if dipindah > 0 {
+			s.cache.Invalidate()
+		}
+	}
+
+	dipakai, err := s.pekerjaanRepo.CountByTaksonomi(ctx, existing.Jenis, existing.Kode)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa pemakaian istilah", "detail": err.Error()})
+	}
+	if dipakai > 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error":  fmt.Sprintf("Istilah %s masih dipakai oleh %d pekerjaan", existing.Kode, dipakai),
+			"detail": "Isi ?ganti=<kode> untuk memindahkan pekerjaan tersebut ke istilah lain",
+		})
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus data", "detail": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Istilah berhasil dihapus", "dipindah": dipindah})
+}
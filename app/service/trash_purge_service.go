@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"praktikummongo/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TrashPurgeService menghapus permanen pekerjaan yang sudah berada di trash
+// lebih lama dari masa retensi.
+type TrashPurgeService struct {
+	repo      repository.IPekerjaanRepository
+	retention time.Duration
+	interval  time.Duration
+
+	mu      sync.Mutex
+	lastRun time.Time
+	nextRun time.Time
+}
+
+func NewTrashPurgeService(repo repository.IPekerjaanRepository, retentionDays int, interval time.Duration) *TrashPurgeService {
+	if retentionDays <= 0 {
+		retentionDays = 30
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &TrashPurgeService{
+		repo:      repo,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		interval:  interval,
+	}
+}
+
+// cutoff adalah batas waktu: data yang dihapus sebelum ini boleh dipurge
+func (s *TrashPurgeService) cutoff(now time.Time) time.Time {
+	return now.Add(-s.retention)
+}
+
+// Start menjalankan worker purge di background sampai ctx dibatalkan
+func (s *TrashPurgeService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (s *TrashPurgeService) runOnce(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	s.mu.Lock()
+	s.lastRun = now
+	s.nextRun = now.Add(s.interval)
+	s.mu.Unlock()
+
+	if _, err := s.Purge(ctx, now); err != nil {
+		log.Println("Trash purge gagal:", err)
+	}
+}
+
+// Purge menghapus permanen semua data trash yang melewati retensi per waktu now
+func (s *TrashPurgeService) Purge(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := s.cutoff(now)
+	expired, err := s.repo.GetExpiredTrash(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(expired))
+	for _, t := range expired {
+		ids = append(ids, t.ID)
+	}
+
+	deleted, err := s.repo.PurgeTrash(ctx, ids, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, t := range expired {
+		log.Printf("Trash purge: pekerjaan %s (%s - %s) dihapus permanen, masuk trash %s",
+			t.ID.Hex(), t.NamaPerusahaan, t.PosisiJabatan, t.IsDeleted.Format(time.RFC3339))
+	}
+	log.Printf("Trash purge selesai: %d pekerjaan dihapus (cutoff %s)", deleted, cutoff.Format(time.RFC3339))
+	return deleted, nil
+}
+
+// ------------------- Handler Admin -------------------
+
+// PreviewPurge menampilkan data yang akan terhapus pada purge berikutnya
+func (s *TrashPurgeService) PreviewPurge(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s.mu.Lock()
+	lastRun, nextRun := s.lastRun, s.nextRun
+	s.mu.Unlock()
+
+	// Preview dihitung terhadap jadwal purge berikutnya, bukan waktu sekarang
+	at := time.Now()
+	if nextRun.After(at) {
+		at = nextRun
+	}
+
+	data, err := s.repo.GetExpiredTrash(ctx, s.cutoff(at))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data", "detail": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"retention_days": int(s.retention.Hours() / 24),
+		"last_run":       lastRun,
+		"next_run":       nextRun,
+		"cutoff":         s.cutoff(at),
+		"total":          len(data),
+		"data":           data,
+	})
+}
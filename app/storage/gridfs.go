@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GridFS menyimpan file di MongoDB. Key dipakai sebagai _id dan nama file GridFS.
+type GridFS struct {
+	bucket *gridfs.Bucket
+}
+
+// NewGridFS membuat bucket GridFS; nama kosong berarti "uploads"
+func NewGridFS(db *mongo.Database, name string) (*GridFS, error) {
+	if name == "" {
+		name = "uploads"
+	}
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(name))
+	if err != nil {
+		return nil, err
+	}
+	return &GridFS{bucket: bucket}, nil
+}
+
+// gridfsFile adalah dokumen di koleksi <bucket>.files
+type gridfsFile struct {
+	ID         string    `bson:"_id"`
+	Length     int64     `bson:"length"`
+	UploadDate time.Time `bson:"uploadDate"`
+	Metadata   struct {
+		ContentType string `bson:"content_type"`
+	} `bson:"metadata"`
+}
+
+func (f *gridfsFile) object() *Object {
+	return &Object{Key: f.ID, Size: f.Length, ContentType: f.Metadata.ContentType, ModTime: f.UploadDate}
+}
+
+// Put mengganti objek lama dengan key yang sama. Karena _id GridFS harus unik,
+// isi diupload dulu dengan _id sementara lalu ditukar setelah upload berhasil,
+// sehingga upload yang gagal tidak menghapus objek lama.
+func (s *GridFS) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	tmpID := gridfsTmpPrefix + primitive.NewObjectID().Hex()
+	opts := options.GridFSUpload().SetMetadata(bson.M{"content_type": contentType})
+	us, err := s.bucket.OpenUploadStreamWithID(tmpID, key, opts)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		us.SetWriteDeadline(deadline)
+	}
+	if _, err := io.Copy(us, ctxReader{ctx, r}); err != nil {
+		us.Abort()
+		return err
+	}
+	if err := us.Close(); err != nil {
+		s.bucket.DeleteContext(context.Background(), tmpID)
+		return err
+	}
+	return s.swap(ctx, tmpID, key)
+}
+
+// gridfsTmpPrefix adalah prefix _id sementara selama Put berjalan
+const gridfsTmpPrefix = ".tmp/"
+
+// swap memindahkan objek tmpID ke key: objek lama dihapus, chunk diarahkan ke key,
+// lalu dokumen files disalin dengan _id key
+func (s *GridFS) swap(ctx context.Context, tmpID, key string) error {
+	files, chunks := s.bucket.GetFilesCollection(), s.bucket.GetChunksCollection()
+
+	var doc bson.M
+	if err := files.FindOne(ctx, bson.M{"_id": tmpID}).Decode(&doc); err != nil {
+		return err
+	}
+	if err := s.bucket.DeleteContext(ctx, key); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		s.bucket.DeleteContext(context.Background(), tmpID)
+		return err
+	}
+	if _, err := chunks.UpdateMany(ctx, bson.M{"files_id": tmpID}, bson.M{"$set": bson.M{"files_id": key}}); err != nil {
+		return err
+	}
+	doc["_id"] = key
+	if _, err := files.InsertOne(ctx, doc); err != nil {
+		return err
+	}
+	_, err := files.DeleteOne(ctx, bson.M{"_id": tmpID})
+	return err
+}
+
+// ctxReader menghentikan upload GridFS saat ctx dibatalkan, karena upload stream
+// driver tidak menerima context
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func (s *GridFS) Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error) {
+	obj, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	stream, err := s.bucket.OpenDownloadStream(key)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	return &gridfsReader{bucket: s.bucket, key: key, size: obj.Size, stream: stream}, obj, nil
+}
+
+func (s *GridFS) Delete(ctx context.Context, key string) error {
+	err := s.bucket.DeleteContext(ctx, key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *GridFS) Stat(ctx context.Context, key string) (*Object, error) {
+	var f gridfsFile
+	err := s.bucket.GetFilesCollection().FindOne(ctx, bson.M{"_id": key}).Decode(&f)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return f.object(), nil
+}
+
+func (s *GridFS) List(ctx context.Context, prefix string) ([]Object, error) {
+	filter := bson.M{}
+	if prefix != "" {
+		filter["_id"] = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+	}
+	cursor, err := s.bucket.GetFilesCollection().Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var objects []Object
+	for cursor.Next(ctx) {
+		var f gridfsFile
+		if err := cursor.Decode(&f); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(f.ID, gridfsTmpPrefix) {
+			// Upload yang sedang berjalan, seperti .upload-* di Local
+			continue
+		}
+		objects = append(objects, *f.object())
+	}
+	return objects, cursor.Err()
+}
+
+// gridfsReader menambahkan Seek pada DownloadStream: maju dengan Skip,
+// mundur dengan membuka ulang stream dari awal
+type gridfsReader struct {
+	bucket *gridfs.Bucket
+	key    string
+	size   int64
+	pos    int64
+	stream *gridfs.DownloadStream
+}
+
+func (r *gridfsReader) Read(p []byte) (int, error) {
+	n, err := r.stream.Read(p)
+	r.pos += int64(n)
+	return n, err
+}
+
+func (r *gridfsReader) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = r.pos + offset
+	case io.SeekEnd:
+		target = r.size + offset
+	}
+	if target < 0 {
+		return 0, errors.New("posisi seek negatif")
+	}
+
+	if target < r.pos {
+		r.stream.Close()
+		stream, err := r.bucket.OpenDownloadStream(r.key)
+		if err != nil {
+			return 0, err
+		}
+		r.stream, r.pos = stream, 0
+	}
+	if target > r.pos {
+		n, err := r.stream.Skip(target - r.pos)
+		r.pos += n
+		if err != nil && err != io.EOF {
+			return r.pos, err
+		}
+	}
+	return r.pos, nil
+}
+
+func (r *gridfsReader) Close() error {
+	return r.stream.Close()
+}
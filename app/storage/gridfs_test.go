@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testGridFS membuka bucket di database sementara di MONGO_URI; test dilewati jika server tidak ada
+func testGridFS(t *testing.T) *GridFS {
+	t.Helper()
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI tidak diatur")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerSelectionTimeout(2*time.Second))
+	if err != nil {
+		t.Skip("MongoDB tidak tersedia:", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		t.Skip("MongoDB tidak tersedia:", err)
+	}
+
+	db := client.Database(fmt.Sprintf("storage_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+	s, err := NewGridFS(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGridFSRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := testGridFS(t)
+
+	put(t, s, "a.txt", "satu")
+	put(t, s, "a.txt", "satu lagi")
+	if got := get(t, s, "a.txt"); got != "satu lagi" {
+		t.Errorf("a.txt setelah ditimpa = %q", got)
+	}
+
+	if err := Move(ctx, s, "a.txt", "karantina/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := keys(t, s, ""); strings.Join(got, ",") != "karantina/a.txt" {
+		t.Errorf("List = %v, want hanya karantina/a.txt", got)
+	}
+	n, err := s.bucket.GetChunksCollection().CountDocuments(ctx, bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("jumlah chunk = %d, want 1 (chunk lama dan sementara terhapus)", n)
+	}
+}
+
+// Put yang gagal di tengah tidak boleh menghapus objek lama
+func TestGridFSPutGagal(t *testing.T) {
+	ctx := context.Background()
+	s := testGridFS(t)
+	put(t, s, "a.txt", "lama")
+
+	r := io.MultiReader(strings.NewReader("baru"), errReader{})
+	if err := s.Put(ctx, "a.txt", r, 8, "text/plain"); err == nil {
+		t.Fatal("Put dengan reader error harus gagal")
+	}
+	if got := get(t, s, "a.txt"); got != "lama" {
+		t.Errorf("a.txt = %q, want isi lama", got)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := s.Put(cancelled, "a.txt", strings.NewReader("baru"), 4, "text/plain"); err == nil {
+		t.Fatal("Put dengan ctx dibatalkan harus gagal")
+	}
+	if got := get(t, s, "a.txt"); got != "lama" {
+		t.Errorf("a.txt = %q, want isi lama", got)
+	}
+}
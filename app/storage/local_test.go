@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func put(t *testing.T, s Storage, key, isi string) {
+	t.Helper()
+	if err := s.Put(context.Background(), key, strings.NewReader(isi), int64(len(isi)), "text/plain"); err != nil {
+		t.Fatalf("Put %s: %v", key, err)
+	}
+}
+
+func get(t *testing.T, s Storage, key string) string {
+	t.Helper()
+	r, obj, err := s.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get %s: %v", key, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Size != int64(len(data)) {
+		t.Errorf("Get %s: size %d, isi %d byte", key, obj.Size, len(data))
+	}
+	return string(data)
+}
+
+func keys(t *testing.T, s Storage, prefix string) []string {
+	t.Helper()
+	objects, err := s.List(context.Background(), prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, o := range objects {
+		out = append(out, o.Key)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	put(t, s, "a.txt", "satu")
+	put(t, s, "dir/b.txt", "dua")
+	if got := get(t, s, "a.txt"); got != "satu" {
+		t.Errorf("a.txt = %q, want satu", got)
+	}
+
+	// Put ke key yang sama mengganti isi
+	put(t, s, "a.txt", "satu lagi")
+	if got := get(t, s, "a.txt"); got != "satu lagi" {
+		t.Errorf("a.txt setelah ditimpa = %q", got)
+	}
+
+	if got := keys(t, s, ""); strings.Join(got, ",") != "a.txt,dir/b.txt" {
+		t.Errorf("List semua = %v", got)
+	}
+	if got := keys(t, s, "dir/"); strings.Join(got, ",") != "dir/b.txt" {
+		t.Errorf("List dir/ = %v", got)
+	}
+
+	if err := Move(ctx, s, "dir/b.txt", "karantina/dir/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(t, s, "karantina/dir/b.txt"); got != "dua" {
+		t.Errorf("setelah Move = %q", got)
+	}
+	if _, err := s.Stat(ctx, "dir/b.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat asal Move = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Delete(ctx, "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Get(ctx, "a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get setelah Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, "a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete kedua = %v, want ErrNotFound", err)
+	}
+}
+
+// Put yang gagal di tengah tidak boleh merusak isi lama atau meninggalkan file sementara
+func TestLocalPutGagal(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	put(t, s, "a.txt", "lama")
+
+	r := io.MultiReader(strings.NewReader("baru"), errReader{})
+	if err := s.Put(context.Background(), "a.txt", r, 8, "text/plain"); err == nil {
+		t.Fatal("Put dengan reader error harus gagal")
+	}
+	if got := get(t, s, "a.txt"); got != "lama" {
+		t.Errorf("a.txt = %q, want isi lama", got)
+	}
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 1 {
+		t.Errorf("isi root = %v, want hanya a.txt", entries)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("koneksi putus") }
+
+func TestLocalTolakKeyTidakValid(t *testing.T) {
+	ctx := context.Background()
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	s, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{
+		"",
+		"../luar.txt",
+		"dir/../../luar.txt",
+		"/etc/passwd",
+		`..\luar.txt`,
+		"dir//a.txt",
+		"./a.txt",
+	} {
+		if err := s.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Errorf("Put %q harus ditolak", key)
+		}
+		if _, _, err := s.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get %q = %v, want error key tidak valid", key, err)
+		}
+		if err := s.Delete(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Delete %q = %v, want error key tidak valid", key, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(parent, "luar.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("file tertulis di luar root")
+	}
+}
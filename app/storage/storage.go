@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNotFound dikembalikan jika objek dengan key tersebut tidak ada
+var ErrNotFound = errors.New("objek storage tidak ditemukan")
+
+// Object adalah metadata satu objek di storage
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage adalah tempat penyimpanan isi file. model.File hanya menyimpan key-nya.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get membuka objek untuk dibaca; reader mendukung Seek agar Range request bisa dilayani.
+	// ctx dipakai selama reader dibaca, jadi jangan dibatalkan sebelum reader ditutup.
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*Object, error)
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// validKey menolak key kosong, absolut, atau yang keluar dari root (..)
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return fmt.Errorf("key storage '%s' tidak valid", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("key storage '%s' tidak valid", key)
+		}
+	}
+	return nil
+}
+
+// Move memindahkan objek ke key lain (misalnya ke karantina) dengan menyalin
+// lalu menghapus aslinya, karena tidak semua backend punya operasi rename
+func Move(ctx context.Context, s Storage, from, to string) error {
+	r, obj, err := s.Get(ctx, from)
+	if err != nil {
+		return err
+	}
+	err = s.Put(ctx, to, r, obj.Size, obj.ContentType)
+	r.Close()
+	if err != nil {
+		return err
+	}
+	return s.Delete(ctx, from)
+}
+
+// NewFromEnv memilih backend dari STORAGE_BACKEND: local (default), s3, atau gridfs
+func NewFromEnv(db *mongo.Database) (Storage, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		root := os.Getenv("LOCAL_STORAGE_PATH")
+		if root == "" {
+			root = "./uploads"
+		}
+		return NewLocal(root)
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		})
+	case "gridfs":
+		return NewGridFS(db, os.Getenv("GRIDFS_BUCKET"))
+	default:
+		return nil, fmt.Errorf("STORAGE_BACKEND '%s' tidak dikenal (local, s3, gridfs)", backend)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"praktikummongo/database"
 	"praktikummongo/route"
@@ -24,9 +26,18 @@ func main() {
 		}
 	}()
 
+	// Worker background berhenti saat menerima SIGINT / SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Inisialisasi Fiber app dan inject DB
-	// Pemanggilan 'route.NewApp(db)' sekarang sudah benar
-	app := config.NewApp(db)
+	app := config.NewApp(ctx, db)
+	go func() {
+		<-ctx.Done()
+		if err := app.Shutdown(); err != nil {
+			log.Println("Gagal menghentikan server:", err)
+		}
+	}()
 
 	// Ambil port dari .env
 	port := os.Getenv("APP_PORT")
@@ -36,5 +47,7 @@ func main() {
 
 	srv := ":" + port
 	log.Println("Server berjalan di http://localhost" + srv)
-	log.Fatal(app.Listen(srv))
+	if err := app.Listen(srv); err != nil {
+		log.Fatal(err)
+	}
 }
\ No newline at end of file
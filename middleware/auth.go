@@ -60,3 +60,17 @@ func RoleMiddleware(allowedRoles ...string) fiber.Handler {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Forbidden: role not allowed"})
 	}
 }
+
+// JWTOrSignedURL mengizinkan request dengan URL bertanda tangan (?expires=&sig=)
+// tanpa header Authorization, misalnya untuk <img src>. Tanpa sig, JWT wajib.
+func JWTOrSignedURL(c *fiber.Ctx) error {
+	sig := c.Query("sig")
+	if sig == "" {
+		return JWTMiddleware(c)
+	}
+	if err := utils.VerifyFileSignature(c.Params("id"), c.Query("expires"), sig); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Locals("signed_url", true)
+	return c.Next()
+}
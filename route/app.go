@@ -1,9 +1,18 @@
 package config
 
 import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
 	"praktikummongo/app/repository"
+	"praktikummongo/app/scanner"
 	"praktikummongo/app/service"
+	"praktikummongo/app/storage"
 	"praktikummongo/middleware"
+	"praktikummongo/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -11,31 +20,112 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func NewApp(db *mongo.Database) *fiber.App {
-	// Modifikasi fiber.New() untuk menyertakan BodyLimit
+// NewApp menyusun service dan route. Worker background (cache statistik, purge
+// trash, pindai file, dll.) berjalan sampai ctx dibatalkan.
+func NewApp(ctx context.Context, db *mongo.Database) *fiber.App {
+	// Batas ukuran per kategori (FILE_SIZE_LIMITS); BodyLimit mengikuti batas terbesar
+	fileSizeLimits, err := utils.ParseBatasUkuran(os.Getenv("FILE_SIZE_LIMITS"))
+	if err != nil {
+		log.Fatal("FILE_SIZE_LIMITS tidak valid:", err)
+	}
+	bodyLimit := service.BodyLimit(service.GabungBatasUkuran(fileSizeLimits))
 	app := fiber.New(fiber.Config{
-		BodyLimit: 10 * 1024 * 1024, // 10MB
+		BodyLimit:    bodyLimit,
+		ErrorHandler: errorHandler(bodyLimit),
 	})
 
-	// --- Middleware & Static ---
+	// --- Middleware ---
 	app.Use(cors.New())
 	app.Use(logger.New())
 
-	app.Static("/uploads", "./uploads")
-
 	// Repository
 	userRepo := repository.NewUserRepository(db)
 	alumniRepo := repository.NewAlumniRepository(db)
 	pekerjaanRepo := repository.NewPekerjaanRepository(db)
 	fileRepo := repository.NewFileRepository(db)
+	statsRepo := repository.NewStatsRepository(db)
+	taksonomiRepo := repository.NewTaksonomiRepository(db)
+	statsCacheRepo := repository.NewStatsCacheRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	importJobRepo := repository.NewImportJobRepository(db)
+	uploadSessionRepo := repository.NewUploadSessionRepository(db)
+	blobRepo := repository.NewBlobRepository(db)
 
 	// Service
 	authService := service.NewAuthService(userRepo)
-	alumniService := service.NewAlumniService(alumniRepo, db)
-	pekerjaanService := service.NewPekerjaanService(pekerjaanRepo)
 
-	uploadPath := "./uploads"                                    
-	fileService := service.NewFileService(fileRepo, uploadPath)
+	// Cache statistik di stats_cache, direfresh tiap STATS_REFRESH_INTERVAL
+	// Entri yang tidak dibaca selama STATS_CACHE_TTL dihapus lewat TTL index
+	statsRefreshInterval, _ := time.ParseDuration(os.Getenv("STATS_REFRESH_INTERVAL"))
+	statsCacheTTL, _ := time.ParseDuration(os.Getenv("STATS_CACHE_TTL"))
+	statsCacheService := service.NewStatsCacheService(statsCacheRepo, statsRefreshInterval, statsCacheTTL)
+	if err := statsCacheService.EnsureIndexes(ctx); err != nil {
+		log.Println("Gagal membuat TTL index stats_cache:", err)
+	}
+	statsCacheService.Start(ctx)
+
+	alumniService := service.NewAlumniService(alumniRepo, statsCacheService)
+	alumniImportService := service.NewAlumniImportService(alumniRepo, importJobRepo, statsCacheService)
+	if err := alumniImportService.FailInterrupted(ctx); err != nil {
+		log.Println("Gagal memperbarui job import yang terputus:", err)
+	}
+	exportService := service.NewExportService(alumniRepo, pekerjaanRepo)
+	pekerjaanService := service.NewPekerjaanService(pekerjaanRepo, alumniRepo, taksonomiRepo, statsCacheService)
+	taksonomiService := service.NewTaksonomiService(taksonomiRepo, pekerjaanRepo, statsCacheService)
+	if err := taksonomiService.EnsureIndexes(ctx); err != nil {
+		log.Println("Gagal membuat index taksonomi:", err)
+	}
+	if err := taksonomiService.EnsureDefaults(ctx); err != nil {
+		log.Println("Gagal mengisi taksonomi awal:", err)
+	}
+
+	// Purge otomatis trash pekerjaan (TRASH_RETENTION_DAYS, TRASH_PURGE_INTERVAL)
+	retentionDays, _ := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	purgeInterval, _ := time.ParseDuration(os.Getenv("TRASH_PURGE_INTERVAL"))
+	trashPurgeService := service.NewTrashPurgeService(pekerjaanRepo, retentionDays, purgeInterval)
+	trashPurgeService.Start(ctx)
+
+	statsService := service.NewStatsService(statsRepo, statsCacheService)
+	searchService := service.NewSearchService(searchRepo, taksonomiRepo)
+	if err := searchService.EnsureIndexes(ctx); err != nil {
+		log.Println("Gagal membuat text index pencarian:", err)
+	}
+	migrationService := service.NewMigrationService(pekerjaanRepo, taksonomiRepo, statsCacheService)
+	if err := migrationService.MigrateTanggalOnStartup(ctx); err != nil {
+		log.Println("Gagal migrasi tanggal pekerjaan:", err)
+	}
+
+	// Backend penyimpanan file dipilih lewat STORAGE_BACKEND (local, s3, gridfs)
+	fileStorage, err := storage.NewFromEnv(db)
+	if err != nil {
+		log.Fatal("Gagal menyiapkan storage file:", err)
+	}
+	// Masa berlaku sesi upload bertahap
+	uploadSessionTTL, _ := time.ParseDuration(os.Getenv("UPLOAD_SESSION_TTL"))
+	// Kuota penyimpanan per role (FILE_QUOTAS), misalnya "user=200MB"
+	fileQuotas, err := utils.ParseBatasUkuran(os.Getenv("FILE_QUOTAS"))
+	if err != nil {
+		log.Fatal("FILE_QUOTAS tidak valid:", err)
+	}
+	// Pemindai malware untuk upload baru (SCANNER_BACKEND: noop, clamav)
+	fileScanner, err := scanner.NewFromEnv()
+	if err != nil {
+		log.Fatal("Gagal menyiapkan pemindai file:", err)
+	}
+	fileScanService := service.NewFileScanService(fileRepo, blobRepo, fileStorage, fileScanner, 0)
+	if err := fileScanService.MigrateScanStatusOnStartup(); err != nil {
+		log.Println("Gagal migrasi status pindai file:", err)
+	}
+	fileScanService.Start(ctx)
+	fileService := service.NewFileService(fileRepo, uploadSessionRepo, blobRepo, userRepo, alumniRepo, pekerjaanRepo, fileStorage, fileScanService,
+		service.FileConfig{SizeLimits: fileSizeLimits, SessionTTL: uploadSessionTTL, RoleQuotas: fileQuotas})
+	fileService.StartUploadPurge(ctx)
+
+	// Pemeriksaan konsistensi files / blobs dengan storage tiap FILE_CHECK_INTERVAL
+	fileCheckInterval, _ := time.ParseDuration(os.Getenv("FILE_CHECK_INTERVAL"))
+	fileConsistencyService := service.NewFileConsistencyService(fileRepo, blobRepo, uploadSessionRepo, fileStorage, fileCheckInterval)
+	fileConsistencyService.Start(ctx)
+	reportService := service.NewReportService(alumniRepo, pekerjaanRepo, statsRepo, taksonomiRepo, fileService)
 
 	// ------------------- ROUTE SETUP -------------------
 
@@ -47,34 +137,115 @@ func NewApp(db *mongo.Database) *fiber.App {
 
 	// ------------------- ALUMNI -------------------
 	alumni := api.Group("/alumni", middleware.JWTMiddleware)
+	// Route statis harus didaftarkan sebelum /:id agar tidak tertangkap sebagai ID
+	alumni.Get("/jumlah-angkatan", middleware.RoleMiddleware("admin", "user"), alumniService.GetJumlahByAngkatan)
+	alumni.Get("/jumlah-pekerjaan", middleware.RoleMiddleware("admin", "user"), alumniService.GetByJumlahPekerjaan)
+	alumni.Post("/import/preview", middleware.RoleMiddleware("admin"), alumniImportService.Preview)
+	alumni.Post("/import", middleware.RoleMiddleware("admin"), alumniImportService.Import)
+	alumni.Get("/import/:jobId", middleware.RoleMiddleware("admin"), alumniImportService.GetJob)
+	alumni.Get("/export", middleware.RoleMiddleware("admin"), exportService.ExportAlumni)
 	alumni.Get("/", middleware.RoleMiddleware("admin", "user"), alumniService.GetAll)
 	alumni.Get("/:id", middleware.RoleMiddleware("admin", "user"), alumniService.GetByID)
+	alumni.Get("/:id/pekerjaan", middleware.RoleMiddleware("admin", "user"), pekerjaanService.GetByAlumniParam)
+	alumni.Get("/:id/files", middleware.RoleMiddleware("admin", "user"), fileService.GetAlumniFiles)
+	alumni.Post("/:id/files", middleware.RoleMiddleware("admin", "user"), fileService.UploadAlumniFile)
 	alumni.Post("/", middleware.RoleMiddleware("admin"), alumniService.Create)
 	alumni.Put("/:id", middleware.RoleMiddleware("admin"), alumniService.Update)
 	alumni.Delete("/:id", middleware.RoleMiddleware("admin"), alumniService.Delete)
 
-	alumni.Get("/jumlah-angkatan", middleware.RoleMiddleware("admin", "user"), alumniService.GetJumlahByAngkatan)
-	alumni.Get("/jumlah-pekerjaan", middleware.RoleMiddleware("admin", "user"), alumniService.GetAlumniDenganDuaPekerjaan)
-
 	// ------------------- PEKERJAAN -------------------
 	pekerjaan := api.Group("/pekerjaan", middleware.JWTMiddleware)
+	// /trash harus didaftarkan sebelum /:id agar tidak tertangkap sebagai ID
+	pekerjaan.Get("/trash", middleware.RoleMiddleware("admin", "user"), pekerjaanService.GetTrash)
+	pekerjaan.Get("/trash/purge-preview", middleware.RoleMiddleware("admin"), trashPurgeService.PreviewPurge)
+	pekerjaan.Post("/trash/restore", middleware.RoleMiddleware("admin", "user"), pekerjaanService.BulkRestore)
+	pekerjaan.Post("/trash/purge", middleware.RoleMiddleware("admin", "user"), pekerjaanService.BulkHardDelete)
+	pekerjaan.Get("/export", middleware.RoleMiddleware("admin"), exportService.ExportPekerjaan)
 	pekerjaan.Get("/", middleware.RoleMiddleware("admin", "user"), pekerjaanService.GetAll)
 	pekerjaan.Get("/:id", middleware.RoleMiddleware("admin", "user"), pekerjaanService.GetByID)
+	pekerjaan.Get("/:id/files", middleware.RoleMiddleware("admin", "user"), fileService.GetPekerjaanFiles)
+	pekerjaan.Post("/:id/files", middleware.RoleMiddleware("admin", "user"), fileService.UploadPekerjaanFile)
 	pekerjaan.Post("/", middleware.RoleMiddleware("admin", "user"), pekerjaanService.Create)
 	pekerjaan.Put("/:id", middleware.RoleMiddleware("admin", "user"), pekerjaanService.Update)
-	pekerjaan.Delete("/:id", middleware.RoleMiddleware("admin", "user"), pekerjaanService.Delete)
-	pekerjaan.Get("/trash", middleware.RoleMiddleware("admin", "user"), pekerjaanService.GetTrash)
+	pekerjaan.Delete("/:id", middleware.RoleMiddleware("admin", "user"), pekerjaanService.DeleteRBAC)
 	pekerjaan.Put("/restore/:id", middleware.RoleMiddleware("admin", "user"), pekerjaanService.Restore)
 	pekerjaan.Delete("/hard/:id", middleware.RoleMiddleware("admin", "user"), pekerjaanService.HardDelete)
 
+	// ------------------- TAKSONOMI -------------------
+	taksonomi := api.Group("/taksonomi", middleware.JWTMiddleware)
+	taksonomi.Get("/:jenis", middleware.RoleMiddleware("admin", "user"), taksonomiService.GetAll)
+	taksonomi.Post("/:jenis", middleware.RoleMiddleware("admin"), taksonomiService.Create)
+	taksonomi.Put("/:jenis/:id", middleware.RoleMiddleware("admin"), taksonomiService.Update)
+	taksonomi.Delete("/:jenis/:id", middleware.RoleMiddleware("admin"), taksonomiService.Delete)
+
+	// ------------------- STATISTIK -------------------
+	stats := api.Group("/stats", middleware.JWTMiddleware, middleware.RoleMiddleware("admin", "user"))
+	stats.Get("/gaji", statsService.GetStatistikGaji)
+	stats.Get("/tingkat-kerja", statsService.GetTingkatKerja)
+	stats.Get("/waktu-tunggu", statsService.GetWaktuTunggu)
+	stats.Get("/perusahaan", statsService.GetTopPerusahaan)
+	stats.Get("/industri", statsService.GetDistribusiIndustri)
+	stats.Get("/lokasi", statsService.GetDistribusiLokasi)
+
+	// ------------------- PENCARIAN -------------------
+	api.Get("/search", middleware.JWTMiddleware, middleware.RoleMiddleware("admin", "user"), searchService.Search)
+
+	// ------------------- LAPORAN PDF -------------------
+	reports := api.Group("/reports", middleware.JWTMiddleware, middleware.RoleMiddleware("admin"))
+	reports.Post("/alumni/:id", reportService.ProfilAlumni)
+	reports.Post("/angkatan/:angkatan", reportService.RingkasanAngkatan)
+
+	// ------------------- ADMIN / MIGRASI -------------------
+	admin := api.Group("/admin", middleware.JWTMiddleware, middleware.RoleMiddleware("admin"))
+	admin.Post("/migrations/tanggal-pekerjaan", migrationService.MigrateTanggalPekerjaan)
+	admin.Post("/migrations/gaji-pekerjaan", migrationService.MigrateGajiPekerjaan)
+	admin.Post("/migrations/taksonomi-pekerjaan", migrationService.MigrateTaksonomiPekerjaan)
+	admin.Post("/migrations/file-variants", fileService.MigrateImageVariants)
+	admin.Post("/migrations/file-hashes", fileService.MigrateFileHashes)
+	admin.Post("/migrations/file-scan-status", fileScanService.MigrateScanStatus)
+	admin.Get("/files/dedup-stats", fileService.GetDedupStats)
+	admin.Get("/files/usage", fileService.GetStorageUsage)
+	admin.Get("/files/consistency", fileConsistencyService.LastReport)
+	admin.Post("/files/consistency", fileConsistencyService.RunCheck)
+	admin.Post("/files/:id/rescan", fileScanService.Rescan)
+	admin.Put("/users/:id/quota", fileService.SetUserQuota)
+	admin.Post("/stats/refresh", statsCacheService.ForceRefresh)
+
+	// ------------------- FILE MILIK USER -------------------
+	api.Get("/me/files", middleware.JWTMiddleware, fileService.GetMyFiles)
+
 	// ------------------- FILE UPLOAD ------------------- // <-- BLOK TAMBAHAN
-	files := api.Group("/files", middleware.JWTMiddleware) 
+	// Download didaftarkan sebelum grup agar URL bertanda tangan tidak butuh JWT
+	api.Get("/files/:id/download", middleware.JWTOrSignedURL, fileService.DownloadFile)
+	files := api.Group("/files", middleware.JWTMiddleware)
 	files.Post("/upload", fileService.UploadFile)
 	files.Get("/", fileService.GetAllFiles)
+	// Upload bertahap untuk file besar, didaftarkan sebelum /:id
+	files.Post("/uploads", fileService.CreateUploadSession)
+	files.Get("/uploads/:id", fileService.GetUploadSession)
+	files.Put("/uploads/:id/chunks/:index", fileService.UploadChunk)
+	files.Post("/uploads/:id/complete", fileService.CompleteUpload)
+	files.Delete("/uploads/:id", fileService.AbortUpload)
 	files.Get("/:id", fileService.GetFileByID)
+	files.Get("/:id/signed-url", fileService.GetSignedURL)
 	files.Delete("/:id", fileService.DeleteFile)
 	// files.Delete("/:id", middleware.RoleMiddleware("admin"), fileService.DeleteFile)
 	// ------------------- AKHIR BLOK -------------------
 
 	return app
-}
\ No newline at end of file
+}
+
+// errorHandler membalas body yang melebihi BodyLimit dengan JSON 413 yang
+// mengarahkan klien ke upload bertahap; error lain memakai handler default Fiber
+func errorHandler(bodyLimit int) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		if e, ok := err.(*fiber.Error); ok && e.Code == fiber.StatusRequestEntityTooLarge {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"success":  false,
+				"message":  "Request body exceeds " + utils.FormatUkuran(int64(bodyLimit)) + ", use chunked upload via POST /api/files/uploads",
+				"max_size": bodyLimit,
+			})
+		}
+		return fiber.DefaultErrorHandler(c, err)
+	}
+}
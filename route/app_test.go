@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"praktikummongo/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newTestApp membuat app dengan MongoDB yang tidak bisa dihubungi; cukup untuk
+// request yang ditolak sebelum menyentuh database
+func newTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+	t.Setenv("LOCAL_STORAGE_PATH", t.TempDir())
+	t.Setenv("STORAGE_BACKEND", "local")
+	t.Setenv("SCANNER_BACKEND", "noop")
+
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	// Menghentikan worker background setelah test selesai
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return NewApp(ctx, client.Database("alumni_route_test"))
+}
+
+func adminToken(t *testing.T) string {
+	t.Helper()
+	token, err := utils.GenerateJWT("000000000000000000000001", "admin", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// Route statis /alumni/jumlah-pekerjaan tidak boleh tertangkap oleh /alumni/:id.
+// Database tidak perlu ada: parameter yang tidak valid ditolak handler laporan
+// sebelum agregasi, sedangkan GetByID akan membalas dengan error yang lain.
+func TestJumlahPekerjaanTidakTertangkapID(t *testing.T) {
+	app := newTestApp(t)
+
+	req := httptest.NewRequest("GET", "/api/alumni/jumlah-pekerjaan?min=5&max=3", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken(t))
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	json.Unmarshal(raw, &body)
+	if resp.StatusCode != 400 || body.Error != "Parameter min/max tidak valid" {
+		t.Fatalf("got %d %s, want 400 dari handler jumlah-pekerjaan", resp.StatusCode, raw)
+	}
+}
+
+// Body di atas BodyLimit dibalas 413 JSON yang menyarankan upload bertahap
+func TestBodyTerlaluBesar(t *testing.T) {
+	// Semua batas kategori 1MB sehingga BodyLimit = potongan terbesar (8MB) + 1MB
+	t.Setenv("FILE_SIZE_LIMITS", "*=1MB,ijazah=1MB,transkrip=1MB,laporan=1MB,surat_keterangan_kerja=1MB,kontrak=1MB")
+	app := newTestApp(t)
+
+	// Lewat listener sungguhan: app.Test mengembalikan error server alih-alih
+	// respons ErrorHandler. Cukup kirim header, batas dicek dari Content-Length.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+	defer app.Shutdown()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "POST /api/files/upload HTTP/1.1\r\nHost: test\r\nAuthorization: Bearer %s\r\nContent-Length: %d\r\n\r\n",
+		adminToken(t), 10<<20)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+
+	var body struct {
+		Message string `json:"message"`
+		MaxSize int    `json:"max_size"`
+	}
+	json.Unmarshal(raw, &body)
+	if resp.StatusCode != 413 || !strings.Contains(body.Message, "/api/files/uploads") || body.MaxSize != 9<<20 {
+		t.Fatalf("got %d %s, want 413 dengan petunjuk upload bertahap dan max_size 9MB", resp.StatusCode, raw)
+	}
+}
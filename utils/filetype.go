@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FileType adalah tipe file yang dikenali dari magic bytes
+type FileType struct {
+	MIME       string
+	Ext        string   // ekstensi yang dipakai saat menyimpan
+	Extensions []string // ekstensi nama file asli yang dianggap cocok
+	magic      [][]byte
+}
+
+// Tipe file yang boleh diupload
+var fileTypes = []FileType{
+	{MIME: "image/jpeg", Ext: ".jpg", Extensions: []string{".jpg", ".jpeg", ".jpe"}, magic: [][]byte{{0xFF, 0xD8, 0xFF}}},
+	{MIME: "image/png", Ext: ".png", Extensions: []string{".png"}, magic: [][]byte{{0x89, 'P', 'N', 'G', 0x0D, 0x0A, 0x1A, 0x0A}}},
+	{MIME: "application/pdf", Ext: ".pdf", Extensions: []string{".pdf"}, magic: [][]byte{[]byte("%PDF-")}},
+}
+
+// Alias Content-Type yang sering dikirim klien
+var mimeAlias = map[string]string{
+	"image/jpg":   "image/jpeg",
+	"image/pjpeg": "image/jpeg",
+}
+
+// DetectFileType mengenali tipe file dari byte awal isinya, bukan dari header klien.
+// Mengembalikan nil jika tipe tidak dikenal / tidak diizinkan.
+func DetectFileType(head []byte) *FileType {
+	for i := range fileTypes {
+		for _, m := range fileTypes[i].magic {
+			if bytes.HasPrefix(head, m) {
+				return &fileTypes[i]
+			}
+		}
+	}
+	return nil
+}
+
+// MatchesContentType memeriksa Content-Type dari klien. Kosong atau
+// application/octet-stream dianggap tidak menyatakan tipe.
+func (t *FileType) MatchesContentType(contentType string) bool {
+	ct := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if ct == "" || ct == "application/octet-stream" {
+		return true
+	}
+	if alias, ok := mimeAlias[ct]; ok {
+		ct = alias
+	}
+	return ct == t.MIME
+}
+
+// MatchesName memeriksa ekstensi nama file asli. Nama tanpa ekstensi diterima.
+func (t *FileType) MatchesName(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return true
+	}
+	for _, e := range t.Extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Panjang maksimum nama file asli yang disimpan (dalam rune)
+const maxFileNameLen = 150
+
+// SanitizeFileName membersihkan nama file dari klien sebelum disimpan dan dipakai
+// di response / header: path dibuang, karakter kontrol (termasuk karakter format
+// seperti RTL override yang bisa menyamarkan ekstensi) dan karakter terlarang
+// diganti '_', spasi dirapikan, dan panjang dibatasi dengan ekstensi dipertahankan.
+func SanitizeFileName(name string) string {
+	if !utf8.ValidString(name) {
+		name = strings.ToValidUTF8(name, "_")
+	}
+	// Buang path Unix maupun Windows
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r), strings.ContainsRune(`<>:"|?*;`, r):
+			return '_'
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return r
+	}, name)
+	name = strings.Join(strings.Fields(name), " ")
+	name = strings.Trim(name, ". ")
+
+	if r := []rune(name); len(r) > maxFileNameLen {
+		ext := []rune(filepath.Ext(name))
+		if len(ext) > 10 {
+			ext = nil
+		}
+		name = string(r[:maxFileNameLen-len(ext)]) + string(ext)
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
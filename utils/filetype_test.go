@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var (
+	headJPEG = []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10, 'J', 'F', 'I', 'F'}
+	headPNG  = []byte{0x89, 'P', 'N', 'G', 0x0D, 0x0A, 0x1A, 0x0A, 0, 0, 0, 0x0D}
+	headPDF  = []byte("%PDF-1.7\n")
+)
+
+func TestDetectFileType(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string // "" = ditolak
+	}{
+		{"jpeg", headJPEG, "image/jpeg"},
+		{"png", headPNG, "image/png"},
+		{"pdf", headPDF, "application/pdf"},
+		{"exe", []byte("MZ\x90\x00\x03"), ""},
+		{"html", []byte("<!DOCTYPE html><script>"), ""},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg">`), ""},
+		{"zip / docx", []byte("PK\x03\x04"), ""},
+		{"pdf diawali spasi", []byte(" %PDF-1.7"), ""},
+		{"magic terpotong", headPNG[:4], ""},
+		{"kosong", nil, ""},
+	}
+	for _, tt := range tests {
+		got := DetectFileType(tt.head)
+		switch {
+		case tt.want == "" && got != nil:
+			t.Errorf("%s: terdeteksi %s, want ditolak", tt.name, got.MIME)
+		case tt.want != "" && (got == nil || got.MIME != tt.want):
+			t.Errorf("%s: got %v, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Isi yang tidak cocok dengan Content-Type / ekstensi yang dinyatakan klien ditolak
+func TestFileTypeTidakCocok(t *testing.T) {
+	png := DetectFileType(headPNG)
+	pdf := DetectFileType(headPDF)
+	jpeg := DetectFileType(headJPEG)
+
+	contentTypes := []struct {
+		t    *FileType
+		ct   string
+		want bool
+	}{
+		{png, "image/png", true},
+		{png, "IMAGE/PNG; charset=binary", true},
+		{png, "", true},
+		{png, "application/octet-stream", true},
+		{jpeg, "image/jpg", true},
+		{png, "image/jpeg", false},
+		{pdf, "text/html", false},
+		{pdf, "application/x-msdownload", false},
+	}
+	for _, tt := range contentTypes {
+		if got := tt.t.MatchesContentType(tt.ct); got != tt.want {
+			t.Errorf("%s MatchesContentType(%q) = %v, want %v", tt.t.MIME, tt.ct, got, tt.want)
+		}
+	}
+
+	names := []struct {
+		t    *FileType
+		name string
+		want bool
+	}{
+		{jpeg, "foto.JPEG", true},
+		{jpeg, "foto", true},
+		{pdf, "cv.pdf", true},
+		{pdf, "cv.pdf.exe", false},
+		{pdf, "cv.html", false},
+		{png, "gambar.jpg", false},
+		{png, "gambar.svg", false},
+	}
+	for _, tt := range names {
+		if got := tt.t.MatchesName(tt.name); got != tt.want {
+			t.Errorf("%s MatchesName(%q) = %v, want %v", tt.t.MIME, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"laporan akhir.pdf", "laporan akhir.pdf"},
+		// Path traversal: hanya nama terakhir yang disimpan
+		{"../../etc/passwd", "passwd"},
+		{"/var/www/../shell.php", "shell.php"},
+		{`C:\Windows\System32\evil.exe`, "evil.exe"},
+		{`..\..\boot.ini`, "boot.ini"},
+		{"dir/", "file"},
+		{"..", "file"},
+		// Karakter kontrol, termasuk CRLF yang bisa menyisipkan header
+		{"a\x00b.pdf", "a_b.pdf"},
+		{"cv.pdf\r\nSet-Cookie: x=1", "cv.pdf__Set-Cookie_ x=1"},
+		{"tab\there.pdf", "tab_here.pdf"},
+		// RTL override menyamarkan "cv\u202efdp.exe" sebagai "cvexe.pdf"
+		{"cv\u202efdp.exe", "cv_fdp.exe"},
+		// Karakter terlarang di nama file / header
+		{`a<b>:"|?*;.pdf`, "a_b_______.pdf"},
+		// Spasi dirapikan, titik dan spasi di ujung dibuang
+		{"  spasi   ganda  .pdf", "spasi ganda .pdf"},
+		{". .hidden", "hidden"},
+		{"nama.", "nama"},
+		{"", "file"},
+		// UTF-8 tidak valid
+		{"\xff\xfe.pdf", "_.pdf"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeFileName(tt.in); got != tt.want {
+			t.Errorf("SanitizeFileName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeFileNamePanjang(t *testing.T) {
+	got := SanitizeFileName(strings.Repeat("é", 300) + ".pdf")
+	if n := utf8.RuneCountInString(got); n != maxFileNameLen {
+		t.Errorf("panjang = %d rune, want %d", n, maxFileNameLen)
+	}
+	if !strings.HasSuffix(got, ".pdf") || !utf8.ValidString(got) {
+		t.Errorf("nama dipotong = %q, want tetap berakhiran .pdf dan UTF-8 valid", got)
+	}
+}
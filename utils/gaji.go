@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"praktikummongo/app/model"
+)
+
+// Satuan nominal yang umum dipakai pada gaji_range lama
+var satuanGaji = map[string]int64{
+	"":       1,
+	"rb":     1_000,
+	"ribu":   1_000,
+	"k":      1_000,
+	"jt":     1_000_000,
+	"juta":   1_000_000,
+	"m":      1_000_000_000,
+	"miliar": 1_000_000_000,
+	"milyar": 1_000_000_000,
+}
+
+var (
+	// angka + satuan opsional, misalnya "5", "5,5 juta", "10jt", "5.000.000"
+	angkaGajiRe = regexp.MustCompile(`(\d+(?:[.,]\d+)*)\s*(rb|ribu|k|jt|juta|miliar|milyar|m)?\b`)
+	currencyRe  = regexp.MustCompile(`\b(idr|rp|usd|sgd|myr|eur|jpy|aud)|\$`)
+	ribuanRe    = regexp.MustCompile(`^\d{1,3}([.,]\d{3})+$`)
+)
+
+// Periode gaji yang diizinkan
+var periodeGaji = map[string]bool{"bulan": true, "tahun": true}
+
+// ParseGajiRange mengubah gaji_range lama seperti "5-10 juta", "Rp 5.000.000 - 7.500.000",
+// "> 10 jt/bulan" atau "USD 1000-2000 per tahun" menjadi model.Gaji.
+// Nominal tanpa satuan di sisi kiri mengikuti satuan di sisi kanan ("5-10 juta").
+func ParseGajiRange(s string) (*model.Gaji, error) {
+	raw := strings.ToLower(strings.TrimSpace(s))
+	if raw == "" {
+		return nil, errors.New("gaji kosong")
+	}
+
+	g := &model.Gaji{Currency: "IDR", Period: "bulan"}
+	if m := currencyRe.FindStringSubmatch(raw); m != nil {
+		switch m[1] {
+		case "", "usd":
+			g.Currency = "USD"
+		case "rp", "idr":
+			g.Currency = "IDR"
+		default:
+			g.Currency = strings.ToUpper(m[1])
+		}
+	}
+	if strings.Contains(raw, "tahun") || strings.Contains(raw, "/thn") || strings.Contains(raw, "annual") {
+		g.Period = "tahun"
+	}
+
+	matches := angkaGajiRe.FindAllStringSubmatch(raw, -1)
+	if len(matches) == 0 || len(matches) > 2 {
+		return nil, fmt.Errorf("format gaji '%s' tidak dikenali", s)
+	}
+
+	values := make([]int64, len(matches))
+	units := make([]string, len(matches))
+	for i, m := range matches {
+		units[i] = m[2]
+	}
+	// "5-10 juta": satuan kiri ikut satuan kanan
+	if len(units) == 2 && units[0] == "" {
+		units[0] = units[1]
+	}
+	for i, m := range matches {
+		v, err := parseNominal(m[1], units[i])
+		if err != nil {
+			return nil, fmt.Errorf("format gaji '%s' tidak dikenali", s)
+		}
+		values[i] = v
+	}
+
+	switch {
+	case len(values) == 2:
+		g.Min, g.Max = values[0], values[1]
+	case strings.ContainsAny(raw, "<") || strings.Contains(raw, "kurang") || strings.Contains(raw, "maks"):
+		g.Max = values[0]
+	default:
+		// "> 10 juta", "10 juta ke atas", atau satu nominal saja
+		g.Min = values[0]
+		if !strings.ContainsAny(raw, ">+") && !strings.Contains(raw, "lebih") && !strings.Contains(raw, "atas") {
+			g.Max = values[0]
+		}
+	}
+
+	if err := ValidateGaji(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// ValidateGaji memeriksa rentang, mata uang, dan periode gaji
+func ValidateGaji(g *model.Gaji) error {
+	if g.Min < 0 || g.Max < 0 {
+		return errors.New("gaji tidak boleh negatif")
+	}
+	if g.Min == 0 && g.Max == 0 {
+		return errors.New("gaji min atau max wajib diisi")
+	}
+	if g.Max != 0 && g.Max < g.Min {
+		return errors.New("gaji max tidak boleh lebih kecil dari min")
+	}
+	if len(g.Currency) != 3 || strings.ToUpper(g.Currency) != g.Currency {
+		return errors.New("currency harus kode ISO 4217 huruf besar, misalnya IDR")
+	}
+	if !periodeGaji[g.Period] {
+		return errors.New("period harus 'bulan' atau 'tahun'")
+	}
+	return nil
+}
+
+// parseNominal membaca "5", "5,5", "7.500.000" dengan satuan opsional
+func parseNominal(num, unit string) (int64, error) {
+	mult := satuanGaji[unit]
+
+	// Pemisah ribuan Indonesia: "5.000.000" atau "5,000,000"
+	if ribuanRe.MatchString(num) {
+		num = strings.NewReplacer(".", "", ",", "").Replace(num)
+	}
+	num = strings.Replace(num, ",", ".", 1)
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(f * float64(mult)), nil
+}
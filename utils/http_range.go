@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeTidakValid berarti header Range tidak bisa dipenuhi (response 416)
+var ErrRangeTidakValid = errors.New("range tidak valid")
+
+// ParseRange membaca header Range satu rentang ("bytes=0-99", "bytes=100-", "bytes=-500")
+// untuk konten sebesar size. ok bernilai false jika header kosong atau berisi banyak
+// rentang; konten lalu dikirim utuh.
+func ParseRange(header string, size int64) (start, length int64, ok bool, err error) {
+	if header == "" {
+		return 0, size, false, nil
+	}
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found {
+		return 0, 0, false, ErrRangeTidakValid
+	}
+	if strings.Contains(spec, ",") {
+		return 0, size, false, nil
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, ErrRangeTidakValid
+	}
+
+	if first == "" {
+		// Suffix range: n byte terakhir
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false, ErrRangeTidakValid
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false, ErrRangeTidakValid
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, ErrRangeTidakValid
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, true, nil
+}
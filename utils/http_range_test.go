@@ -0,0 +1,61 @@
+package utils
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	const size = 1000
+	tests := []struct {
+		header        string
+		start, length int64
+		ok            bool
+		err           error
+	}{
+		// Tanpa header: kirim utuh
+		{"", 0, size, false, nil},
+
+		{"bytes=0-99", 0, 100, true, nil},
+		{"bytes=500-500", 500, 1, true, nil},
+		{"bytes= 10-19", 10, 10, true, nil},
+
+		// Open-ended: sampai akhir konten
+		{"bytes=900-", 900, 100, true, nil},
+		{"bytes=0-", 0, size, true, nil},
+
+		// Suffix: n byte terakhir, dipotong ke ukuran konten
+		{"bytes=-100", 900, 100, true, nil},
+		{"bytes=-5000", 0, size, true, nil},
+		{"bytes=-0", 0, 0, false, ErrRangeTidakValid},
+
+		// Akhir melewati konten dipotong ke byte terakhir
+		{"bytes=990-2000", 990, 10, true, nil},
+
+		// Banyak rentang tidak didukung: kirim utuh
+		{"bytes=0-9,20-29", 0, size, false, nil},
+		{"bytes=-1,0-", 0, size, false, nil},
+
+		// Di luar konten atau tidak valid: 416
+		{"bytes=1000-", 0, 0, false, ErrRangeTidakValid},
+		{"bytes=5000-6000", 0, 0, false, ErrRangeTidakValid},
+		{"bytes=100-50", 0, 0, false, ErrRangeTidakValid},
+		{"bytes=-", 0, 0, false, ErrRangeTidakValid},
+		{"bytes=a-b", 0, 0, false, ErrRangeTidakValid},
+		{"bytes=-1-2", 0, 0, false, ErrRangeTidakValid},
+		{"bytes=10", 0, 0, false, ErrRangeTidakValid},
+		{"items=0-9", 0, 0, false, ErrRangeTidakValid},
+		{" bytes=0-9", 0, 0, false, ErrRangeTidakValid},
+		{"bytes=9223372036854775807-", 0, 0, false, ErrRangeTidakValid},
+	}
+	for _, tt := range tests {
+		start, length, ok, err := ParseRange(tt.header, size)
+		if err != tt.err {
+			t.Errorf("%q: err = %v, want %v", tt.header, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if start != tt.start || length != tt.length || ok != tt.ok {
+			t.Errorf("%q = (%d, %d, %v), want (%d, %d, %v)", tt.header, start, length, ok, tt.start, tt.length, tt.ok)
+		}
+	}
+}
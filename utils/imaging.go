@@ -0,0 +1,264 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// Batas piksel gambar yang mau didecode, untuk mencegah decompression bomb
+const maxImagePixels = 50_000_000
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', 0x0D, 0x0A, 0x1A, 0x0A}
+
+// ------------------- Metadata -------------------
+
+// JPEGOrientation membaca tag Orientation (1-8) dari EXIF JPEG; 1 jika tidak ada
+func JPEGOrientation(data []byte) int {
+	o := 1
+	walkJPEG(data, func(marker byte, seg []byte) bool {
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			o = exifOrientation(seg[6:])
+			return false
+		}
+		return true
+	})
+	return o
+}
+
+// walkJPEG memanggil fn untuk tiap segmen sebelum SOS (data gambar).
+// seg berisi isi segmen tanpa marker dan panjang. fn mengembalikan false untuk berhenti.
+func walkJPEG(data []byte, fn func(marker byte, seg []byte) bool) (sos int) {
+	i := 2
+	for i+4 <= len(data) && data[i] == 0xFF {
+		marker := data[i+1]
+		if marker == 0xDA { // SOS
+			return i
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if n < 2 || i+2+n > len(data) {
+			return -1
+		}
+		if !fn(marker, data[i+4:i+2+n]) {
+			return -1
+		}
+		i += 2 + n
+	}
+	return -1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(bo.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(bo.Uint16(tiff[ifd : ifd+2]))
+	for e := 0; e < count; e++ {
+		off := ifd + 2 + e*12
+		if off+12 > len(tiff) {
+			break
+		}
+		if bo.Uint16(tiff[off:off+2]) == 0x0112 {
+			if o := int(bo.Uint16(tiff[off+8 : off+10])); o >= 1 && o <= 8 {
+				return o
+			}
+		}
+	}
+	return 1
+}
+
+// orientationSegment membuat APP1 EXIF minimal yang hanya berisi tag Orientation
+func orientationSegment(o int) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // header big-endian, IFD0 di offset 8
+		0, 1, // 1 entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, byte(o), 0, 0, // Orientation SHORT
+		0, 0, 0, 0, // tidak ada IFD berikutnya
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// StripJPEGMetadata membuang EXIF (termasuk GPS), XMP, IPTC, dan komentar tanpa
+// meng-encode ulang gambar. Profil warna (ICC, Adobe) dipertahankan, dan
+// orientasi disimpan kembali sebagai EXIF minimal agar tampilan tidak berubah.
+func StripJPEGMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errors.New("bukan file JPEG")
+	}
+	orientation := JPEGOrientation(data)
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	if orientation != 1 {
+		out.Write(orientationSegment(orientation))
+	}
+	sos := walkJPEG(data, func(marker byte, seg []byte) bool {
+		switch {
+		case marker == 0xE1, marker == 0xED, marker == 0xFE: // EXIF/XMP, IPTC, komentar
+		default:
+			out.Write([]byte{0xFF, marker, 0, 0})
+			b := out.Bytes()
+			binary.BigEndian.PutUint16(b[len(b)-2:], uint16(len(seg)+2))
+			out.Write(seg)
+		}
+		return true
+	})
+	if sos < 0 {
+		return nil, errors.New("struktur JPEG tidak valid")
+	}
+	out.Write(data[sos:])
+	return out.Bytes(), nil
+}
+
+// StripPNGMetadata membuang chunk eXIf, teks, dan waktu dari PNG
+func StripPNGMetadata(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errors.New("bukan file PNG")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, errors.New("struktur PNG tidak valid")
+		}
+		n := int(binary.BigEndian.Uint32(data[i : i+4]))
+		end := i + 12 + n
+		if n < 0 || end > len(data) {
+			return nil, errors.New("struktur PNG tidak valid")
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
+
+// ------------------- Variant -------------------
+
+// ImageVariant adalah hasil resize yang sudah di-encode tanpa metadata
+type ImageVariant struct {
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// ResizeImage memperkecil gambar JPEG / PNG agar muat di maxW x maxH (tanpa
+// memperbesar), menerapkan orientasi EXIF, lalu meng-encode ulang dengan format yang sama
+func ResizeImage(data []byte, mime string, maxW, maxH int) (*ImageVariant, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, errors.New("resolusi gambar terlalu besar")
+	}
+
+	var src image.Image
+	orientation := 1
+	switch mime {
+	case "image/jpeg":
+		orientation = JPEGOrientation(data)
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		src, err = png.Decode(bytes.NewReader(data))
+	default:
+		return nil, errors.New("tipe gambar tidak didukung: " + mime)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Orientasi 5-8 menukar lebar dan tinggi, jadi batas ikut ditukar sebelum resize
+	if orientation >= 5 {
+		maxW, maxH = maxH, maxW
+	}
+	img := orient(fit(src, maxW, maxH), orientation)
+
+	var buf bytes.Buffer
+	if mime == "image/png" {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	return &ImageVariant{Data: buf.Bytes(), Width: b.Dx(), Height: b.Dy()}, nil
+}
+
+// fit memperkecil gambar dengan rasio tetap
+func fit(src image.Image, maxW, maxH int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxW && h <= maxH {
+		return src
+	}
+	scale := min(float64(maxW)/float64(w), float64(maxH)/float64(h))
+	nw, nh := max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, nw, nh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+// orient memutar / membalik gambar sesuai tag Orientation EXIF (1-8)
+func orient(src image.Image, o int) image.Image {
+	if o <= 1 || o > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch o {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, src.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
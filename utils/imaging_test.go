@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// Penanda data pribadi di metadata yang harus hilang setelah dibersihkan
+const rahasia = "GPS-LAT-6.2088-LNG-106.8456"
+
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	return img
+}
+
+// jpegSegment membuat segmen JPEG dengan marker dan isi tertentu
+func jpegSegment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// jpegDenganMetadata menyisipkan EXIF (orientasi o + data rahasia), XMP, dan komentar setelah SOI
+func jpegDenganMetadata(t *testing.T, w, h, o int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	exif := orientationSegment(o)
+	exif = append(exif[4:], rahasia...) // isi tambahan setelah IFD
+	meta := jpegSegment(0xE1, exif)
+	meta = append(meta, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00"+rahasia))...)
+	meta = append(meta, jpegSegment(0xFE, []byte(rahasia))...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, meta...)
+	return append(out, data[2:]...)
+}
+
+// pngChunk membuat chunk PNG lengkap dengan CRC
+func pngChunk(typ string, data []byte) []byte {
+	c := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(c, uint32(len(data)))
+	copy(c[4:], typ)
+	c = append(c, data...)
+	return binary.BigEndian.AppendUint32(c, crc32.ChecksumIEEE(c[4:]))
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	for _, o := range []int{1, 6} {
+		data := jpegDenganMetadata(t, 40, 20, o)
+		if JPEGOrientation(data) != o {
+			t.Fatalf("fixture orientasi %d tidak terbaca", o)
+		}
+
+		clean, err := StripJPEGMetadata(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(clean, []byte(rahasia)) {
+			t.Errorf("orientasi %d: metadata masih ada setelah dibersihkan", o)
+		}
+		// Orientasi 1 tidak perlu EXIF sama sekali; selain itu hanya EXIF minimal
+		if hasExif := bytes.Contains(clean, []byte("Exif\x00\x00")); hasExif != (o != 1) {
+			t.Errorf("orientasi %d: EXIF ada = %v", o, hasExif)
+		}
+		if got := JPEGOrientation(clean); got != o {
+			t.Errorf("orientasi setelah dibersihkan = %d, want %d", got, o)
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(clean)); err != nil {
+			t.Errorf("orientasi %d: hasil tidak bisa didecode: %v", o, err)
+		}
+	}
+}
+
+func TestStripJPEGMetadataTidakValid(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		[]byte("%PDF-1.7"),
+		{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF}, // panjang segmen melewati data
+	} {
+		if _, err := StripJPEGMetadata(data); err == nil {
+			t.Errorf("StripJPEGMetadata(% x) harus gagal", data)
+		}
+	}
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(10, 10)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	ihdrEnd := len(pngSignature) + 12 + 13
+
+	var withMeta []byte
+	withMeta = append(withMeta, data[:ihdrEnd]...)
+	withMeta = append(withMeta, pngChunk("tEXt", []byte("Comment\x00"+rahasia))...)
+	withMeta = append(withMeta, pngChunk("eXIf", append([]byte("MM\x00\x2a"), rahasia...))...)
+	withMeta = append(withMeta, pngChunk("tIME", []byte{0x07, 0xE8, 1, 2, 3, 4, 5})...)
+	withMeta = append(withMeta, data[ihdrEnd:]...)
+	if _, err := png.Decode(bytes.NewReader(withMeta)); err != nil {
+		t.Fatalf("fixture PNG tidak valid: %v", err)
+	}
+
+	clean, err := StripPNGMetadata(withMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(clean, data) {
+		t.Errorf("hasil (%d byte) berbeda dari PNG tanpa metadata (%d byte)", len(clean), len(data))
+	}
+	if _, err := StripPNGMetadata(withMeta[:len(withMeta)-3]); err == nil {
+		t.Error("PNG terpotong harus gagal")
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	// Orientasi 6 (putar 90°): 40x20 tampil sebagai 20x40
+	data := jpegDenganMetadata(t, 40, 20, 6)
+	v, err := ResizeImage(data, "image/jpeg", 10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Width != 5 || v.Height != 10 {
+		t.Errorf("ukuran = %dx%d, want 5x10", v.Width, v.Height)
+	}
+	if bytes.Contains(v.Data, []byte("Exif")) || bytes.Contains(v.Data, []byte(rahasia)) {
+		t.Error("variant tidak boleh membawa metadata")
+	}
+
+	// Tidak diperbesar
+	v, err = ResizeImage(data, "image/jpeg", 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Width != 20 || v.Height != 40 {
+		t.Errorf("ukuran = %dx%d, want 20x40", v.Width, v.Height)
+	}
+}
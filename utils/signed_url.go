@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Kunci HMAC untuk URL file bertanda tangan. FILE_URL_SECRET dipakai jika diset,
+// selain itu kunci JWT.
+func signedURLKey() []byte {
+	if k := os.Getenv("FILE_URL_SECRET"); k != "" {
+		return []byte(k)
+	}
+	return JwtKey
+}
+
+func fileSignature(fileID string, expires int64) string {
+	mac := hmac.New(sha256.New, signedURLKey())
+	mac.Write([]byte(fileID + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignFileURL membuat tanda tangan untuk download file yang berlaku selama ttl
+func SignFileURL(fileID string, ttl time.Duration) (expires int64, sig string) {
+	expires = time.Now().Add(ttl).Unix()
+	return expires, fileSignature(fileID, expires)
+}
+
+// VerifyFileSignature memeriksa parameter expires dan sig dari URL bertanda tangan
+func VerifyFileSignature(fileID, expires, sig string) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("parameter expires tidak valid")
+	}
+	if time.Now().Unix() > exp {
+		return errors.New("URL sudah kedaluwarsa")
+	}
+	if !hmac.Equal([]byte(sig), []byte(fileSignature(fileID, exp))) {
+		return errors.New("tanda tangan URL tidak valid")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyFileSignature(t *testing.T) {
+	t.Setenv("FILE_URL_SECRET", "rahasia-test")
+	const id = "65f000000000000000000001"
+
+	exp, sig := SignFileURL(id, time.Minute)
+	expires := strconv.FormatInt(exp, 10)
+	if err := VerifyFileSignature(id, expires, sig); err != nil {
+		t.Fatalf("URL valid ditolak: %v", err)
+	}
+
+	tampered := []byte(sig)
+	tampered[0] ^= 1
+	tests := []struct {
+		name                 string
+		fileID, expires, sig string
+	}{
+		{"sig diubah", id, expires, string(tampered)},
+		{"sig kosong", id, expires, ""},
+		{"file lain", "65f000000000000000000002", expires, sig},
+		{"expires diperpanjang", id, strconv.FormatInt(exp+3600, 10), sig},
+		{"expires bukan angka", id, "besok", sig},
+	}
+	for _, tt := range tests {
+		if err := VerifyFileSignature(tt.fileID, tt.expires, tt.sig); err == nil {
+			t.Errorf("%s: harus ditolak", tt.name)
+		}
+	}
+}
+
+func TestVerifyFileSignatureKedaluwarsa(t *testing.T) {
+	t.Setenv("FILE_URL_SECRET", "rahasia-test")
+	const id = "65f000000000000000000001"
+
+	exp, sig := SignFileURL(id, -time.Second)
+	if err := VerifyFileSignature(id, strconv.FormatInt(exp, 10), sig); err == nil {
+		t.Error("URL kedaluwarsa harus ditolak")
+	}
+}
+
+// Tanda tangan dari kunci lain (misalnya FILE_URL_SECRET diganti) tidak berlaku
+func TestVerifyFileSignatureKunciLain(t *testing.T) {
+	const id = "65f000000000000000000001"
+	t.Setenv("FILE_URL_SECRET", "kunci-lama")
+	exp, sig := SignFileURL(id, time.Minute)
+
+	t.Setenv("FILE_URL_SECRET", "kunci-baru")
+	if err := VerifyFileSignature(id, strconv.FormatInt(exp, 10), sig); err == nil {
+		t.Error("tanda tangan dari kunci lama harus ditolak")
+	}
+}
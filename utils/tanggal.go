@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Nama bulan Indonesia (dan singkatannya) ke bulan dalam bahasa Inggris,
+// agar bisa diparse dengan layout Go biasa.
+var bulanIndonesia = map[string]string{
+	"januari": "January", "jan": "January",
+	"februari": "February", "pebruari": "February", "feb": "February", "peb": "February",
+	"maret": "March", "mar": "March",
+	"april": "April", "apr": "April",
+	"mei":  "May",
+	"juni": "June", "jun": "June",
+	"juli": "July", "jul": "July",
+	"agustus": "August", "agu": "August", "agt": "August", "ags": "August", "aug": "August",
+	"september": "September", "sep": "September", "sept": "September",
+	"oktober": "October", "okt": "October", "oct": "October",
+	"november": "November", "nov": "November", "nop": "November",
+	"desember": "December", "des": "December", "dec": "December",
+}
+
+// Layout yang dicoba berurutan setelah nama bulan dinormalisasi
+var tanggalLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"02-01-2006",
+	"02/01/2006",
+	"2-1-2006",
+	"2/1/2006",
+	"02.01.2006",
+	"2 January 2006",
+	"02 January 2006",
+	"January 2, 2006",
+	"January 2006",
+	"01/2006",
+	"1/2006",
+	"2006-01",
+	"2006",
+}
+
+// Kata yang berarti pekerjaan masih berjalan (tanggal selesai kosong)
+var tanggalBerjalan = map[string]bool{
+	"": true, "-": true, "sekarang": true, "saat ini": true, "present": true,
+	"now": true, "current": true, "masih bekerja": true,
+}
+
+// ErrTanggalTidakValid dikembalikan jika string tidak cocok dengan format apa pun
+var ErrTanggalTidakValid = errors.New("format tanggal tidak dikenali")
+
+// ParseTanggal mengubah string tanggal dalam format ISO atau format Indonesia
+// umum ("15 Januari 2020", "15/01/2020", "Agustus 2019") menjadi time.Time UTC.
+func ParseTanggal(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, ErrTanggalTidakValid
+	}
+
+	normalized := normalizeBulan(s)
+	for _, layout := range tanggalLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, ErrTanggalTidakValid
+}
+
+// ParseTanggalOpsional seperti ParseTanggal, tetapi string kosong atau kata
+// seperti "sekarang" menghasilkan nil (pekerjaan masih berjalan).
+func ParseTanggalOpsional(s string) (*time.Time, error) {
+	if tanggalBerjalan[strings.ToLower(strings.TrimSpace(s))] {
+		return nil, nil
+	}
+	t, err := ParseTanggal(s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// normalizeBulan mengganti nama bulan Indonesia dengan nama bulan Inggris
+func normalizeBulan(s string) string {
+	words := strings.Fields(strings.NewReplacer(",", ", ").Replace(s))
+	for i, w := range words {
+		key := strings.ToLower(strings.Trim(w, ".,"))
+		if en, ok := bulanIndonesia[key]; ok {
+			suffix := ""
+			if strings.HasSuffix(w, ",") {
+				suffix = ","
+			}
+			words[i] = en + suffix
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var satuanUkuran = []struct {
+	suffix string
+	kali   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseUkuran membaca ukuran seperti "25MB", "512KB", atau "1048576" (byte)
+func ParseUkuran(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	kali := int64(1)
+	for _, u := range satuanUkuran {
+		if strings.HasSuffix(v, u.suffix) {
+			v, kali = strings.TrimSpace(strings.TrimSuffix(v, u.suffix)), u.kali
+			break
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("ukuran '%s' tidak valid", s)
+	}
+	return n * kali, nil
+}
+
+// FormatUkuran menampilkan ukuran byte dengan satuan terbesar yang pas, misalnya "10MB"
+func FormatUkuran(n int64) string {
+	for _, u := range satuanUkuran {
+		if n >= u.kali && n%u.kali == 0 {
+			return strconv.FormatInt(n/u.kali, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+// ParseBatasUkuran membaca daftar batas per kategori, misalnya "ijazah=50MB,*=10MB".
+// String kosong menghasilkan map kosong.
+func ParseBatasUkuran(s string) (map[string]int64, error) {
+	batas := map[string]int64{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kategori, ukuran, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("batas ukuran '%s' harus berformat kategori=ukuran", item)
+		}
+		n, err := ParseUkuran(ukuran)
+		if err != nil {
+			return nil, err
+		}
+		batas[strings.TrimSpace(kategori)] = n
+	}
+	return batas, nil
+}